@@ -0,0 +1,82 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/tiagomelo/go-retryable-httpclient/httpclient/adaptive"
+)
+
+// adaptiveTransport is an http.RoundTripper that lets every
+// attempt, including the retries, through the client adaptive
+// concurrency limiter. The latency of an attempt is measured until
+// its response headers arrive, while it stays in flight until its
+// response body is closed.
+type adaptiveTransport struct {
+	next    http.RoundTripper
+	limiter *adaptive.Limiter
+}
+
+// RoundTrip lets the request through the adaptive
+// concurrency limiter and sends it.
+func (t *adaptiveTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	done, err := t.limiter.Acquire(req.URL.Host)
+	if err != nil {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, err
+	}
+	start := now()
+	resp, err := t.next.RoundTrip(req)
+	latency := now().Sub(start)
+	outcome := attemptOutcome(req.Context(), resp, err)
+	if err != nil || resp.Body == nil {
+		done(outcome, latency)
+		return resp, err
+	}
+	resp.Body = &releaseOnClose{ReadCloser: resp.Body, release: func() { done(outcome, latency) }}
+	return resp, nil
+}
+
+// attemptOutcome returns the outcome of an attempt as seen by the
+// adaptive concurrency limiter. Errors, including deadlines exceeded
+// while waiting for the upstream, 429 and 5xx responses mean the
+// upstream is overloaded, while canceled attempts say nothing about it.
+func attemptOutcome(ctx context.Context, resp *http.Response, err error) adaptive.Outcome {
+	if isCanceled(ctx, err) {
+		return adaptive.Ignored
+	}
+	if err != nil {
+		return adaptive.Dropped
+	}
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError {
+		return adaptive.Dropped
+	}
+	return adaptive.Success
+}
+
+// isCanceled reports whether the attempt made with the given
+// context was canceled by the caller, as opposed to timed out.
+func isCanceled(ctx context.Context, err error) bool {
+	return errors.Is(ctx.Err(), context.Canceled) || errors.Is(err, context.Canceled)
+}
+
+// adaptiveClient returns a copy of the given http client whose
+// transport goes through the client adaptive concurrency limiter.
+func (c *Client) adaptiveClient(httpClient *http.Client) *http.Client {
+	if c.concurrencyLimiter == nil {
+		return httpClient
+	}
+	next := httpClient.Transport
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	hc := *httpClient
+	hc.Transport = &adaptiveTransport{
+		next:    next,
+		limiter: c.concurrencyLimiter,
+	}
+	return &hc
+}
@@ -0,0 +1,200 @@
+// Package adaptive provides a concurrency limiter that discovers
+// how many requests in flight each host can take, by growing the
+// limit additively while requests succeed quickly and shrinking it
+// multiplicatively when they fail or slow down (AIMD).
+// It can be used with httpclient.WithAdaptiveConcurrency.
+package adaptive
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ErrLimitExceeded is returned when a request is rejected
+// because the concurrency limit has been reached.
+var ErrLimitExceeded = errors.New("adaptive concurrency limit exceeded")
+
+// Default settings.
+const (
+	DefaultInitialLimit     = 20
+	DefaultMinLimit         = 1
+	DefaultMaxLimit         = 200
+	DefaultBackoffRatio     = 0.9
+	DefaultLatencyThreshold = 2 * time.Second
+)
+
+// Outcome represents how a request went.
+type Outcome int
+
+const (
+	// Success grows the limit, unless the request was slow.
+	Success Outcome = iota
+	// Dropped shrinks the limit, e.g. on errors or overload responses.
+	Dropped
+	// Ignored leaves the limit as is, e.g. on canceled requests.
+	Ignored
+)
+
+// Settings configures a Limiter.
+type Settings struct {
+	// InitialLimit is the limit each key starts with.
+	// Defaults to DefaultInitialLimit.
+	InitialLimit int
+	// MinLimit is the lowest the limit may go.
+	// Defaults to DefaultMinLimit.
+	MinLimit int
+	// MaxLimit is the highest the limit may go.
+	// Defaults to DefaultMaxLimit.
+	MaxLimit int
+	// BackoffRatio is the factor the limit is multiplied by when a
+	// request is dropped. Defaults to DefaultBackoffRatio.
+	BackoffRatio float64
+	// LatencyThreshold is how long a request may take before it
+	// counts as dropped. Defaults to DefaultLatencyThreshold.
+	LatencyThreshold time.Duration
+	// OnLimitChange, if provided, is called whenever the
+	// limit for the given key changes.
+	OnLimitChange func(key string, from, to int)
+}
+
+// Stats holds the state of the limit for a single key.
+type Stats struct {
+	// Limit is the number of requests currently allowed in flight.
+	Limit int
+	// InFlight is the number of requests in flight.
+	InFlight int
+	// Rejected is the number of requests rejected since
+	// the Limiter was created.
+	Rejected int
+}
+
+// limit holds the state of a single key.
+type limit struct {
+	limit    int
+	inFlight int
+	rejected int
+}
+
+// Limiter keeps one limit per key, e.g. per host.
+// It is safe for concurrent use.
+type Limiter struct {
+	settings Settings
+	mu       sync.Mutex
+	limits   map[string]*limit
+}
+
+// New returns a new Limiter.
+func New(settings Settings) *Limiter {
+	if settings.MinLimit <= 0 {
+		settings.MinLimit = DefaultMinLimit
+	}
+	if settings.MaxLimit <= 0 {
+		settings.MaxLimit = DefaultMaxLimit
+	}
+	if settings.MaxLimit < settings.MinLimit {
+		settings.MaxLimit = settings.MinLimit
+	}
+	if settings.InitialLimit <= 0 {
+		settings.InitialLimit = DefaultInitialLimit
+	}
+	settings.InitialLimit = clamp(settings.InitialLimit, settings.MinLimit, settings.MaxLimit)
+	if settings.BackoffRatio <= 0 || settings.BackoffRatio >= 1 {
+		settings.BackoffRatio = DefaultBackoffRatio
+	}
+	if settings.LatencyThreshold <= 0 {
+		settings.LatencyThreshold = DefaultLatencyThreshold
+	}
+	return &Limiter{
+		settings: settings,
+		limits:   make(map[string]*limit),
+	}
+}
+
+// clamp returns n bounded by min and max.
+func clamp(n, min, max int) int {
+	if n < min {
+		return min
+	}
+	if n > max {
+		return max
+	}
+	return n
+}
+
+// limit returns the limit for the given key.
+// It must be called with the lock held.
+func (l *Limiter) limit(key string) *limit {
+	lim, ok := l.limits[key]
+	if !ok {
+		lim = &limit{limit: l.settings.InitialLimit}
+		l.limits[key] = lim
+	}
+	return lim
+}
+
+// Acquire lets a request for the given key in flight, returning a
+// function that must be called with its outcome and latency once it
+// is done. The latency is measured by the caller, so that it only
+// covers the upstream, e.g. until the response headers arrived,
+// and not how long the response body takes to be consumed.
+// It returns ErrLimitExceeded right away when the limit is reached.
+func (l *Limiter) Acquire(key string) (func(outcome Outcome, latency time.Duration), error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	lim := l.limit(key)
+	if lim.inFlight >= lim.limit {
+		lim.rejected++
+		return nil, ErrLimitExceeded
+	}
+	lim.inFlight++
+	var once sync.Once
+	return func(outcome Outcome, latency time.Duration) {
+		once.Do(func() {
+			l.done(key, outcome, latency)
+		})
+	}, nil
+}
+
+// done takes a request out of flight, adjusting the
+// limit for the given key according to its outcome.
+func (l *Limiter) done(key string, outcome Outcome, latency time.Duration) {
+	l.mu.Lock()
+	lim := l.limit(key)
+	inFlight := lim.inFlight
+	lim.inFlight--
+	from := lim.limit
+	if outcome == Success && latency > l.settings.LatencyThreshold {
+		outcome = Dropped
+	}
+	switch outcome {
+	case Success:
+		// The limit only grows when it is being used,
+		// otherwise it would grow without bounds.
+		if inFlight*2 >= lim.limit {
+			lim.limit++
+		}
+	case Dropped:
+		lim.limit = int(math.Floor(float64(lim.limit) * l.settings.BackoffRatio))
+	}
+	lim.limit = clamp(lim.limit, l.settings.MinLimit, l.settings.MaxLimit)
+	to := lim.limit
+	l.mu.Unlock()
+	if from != to && l.settings.OnLimitChange != nil {
+		l.settings.OnLimitChange(key, from, to)
+	}
+}
+
+// Stats returns the state of the limit for the given key.
+func (l *Limiter) Stats(key string) Stats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	lim := l.limit(key)
+	return Stats{
+		Limit:    lim.limit,
+		InFlight: lim.inFlight,
+		Rejected: lim.rejected,
+	}
+}
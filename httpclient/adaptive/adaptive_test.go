@@ -0,0 +1,100 @@
+package adaptive
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimiter(t *testing.T) {
+	testCases := []struct {
+		name          string
+		inFlight      int
+		outcome       Outcome
+		latency       time.Duration
+		expectedLimit int
+	}{
+		{
+			name:          "success grows the limit",
+			inFlight:      2,
+			outcome:       Success,
+			latency:       10 * time.Millisecond,
+			expectedLimit: 5,
+		},
+		{
+			name:          "success doesn't grow an unused limit",
+			inFlight:      1,
+			outcome:       Success,
+			latency:       10 * time.Millisecond,
+			expectedLimit: 4,
+		},
+		{
+			name:          "slow success shrinks the limit",
+			inFlight:      2,
+			outcome:       Success,
+			latency:       time.Second,
+			expectedLimit: 2,
+		},
+		{
+			name:          "dropped shrinks the limit",
+			inFlight:      1,
+			outcome:       Dropped,
+			latency:       10 * time.Millisecond,
+			expectedLimit: 2,
+		},
+		{
+			name:          "ignored leaves the limit as is",
+			inFlight:      2,
+			outcome:       Ignored,
+			latency:       10 * time.Millisecond,
+			expectedLimit: 4,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var changes [][2]int
+			l := New(Settings{
+				InitialLimit:     4,
+				MinLimit:         2,
+				MaxLimit:         5,
+				BackoffRatio:     0.5,
+				LatencyThreshold: 100 * time.Millisecond,
+				OnLimitChange: func(key string, from, to int) {
+					require.Equal(t, "host", key)
+					changes = append(changes, [2]int{from, to})
+				},
+			})
+			var dones []func(Outcome, time.Duration)
+			for i := 0; i < tc.inFlight; i++ {
+				done, err := l.Acquire("host")
+				require.NoError(t, err)
+				dones = append(dones, done)
+			}
+			dones[0](tc.outcome, tc.latency)
+			dones[0](tc.outcome, tc.latency)
+			require.Equal(t, Stats{Limit: tc.expectedLimit, InFlight: tc.inFlight - 1}, l.Stats("host"))
+			if tc.expectedLimit != 4 {
+				require.Equal(t, [][2]int{{4, tc.expectedLimit}}, changes)
+			} else {
+				require.Empty(t, changes)
+			}
+		})
+	}
+}
+
+func TestLimiterRejectsOverLimit(t *testing.T) {
+	l := New(Settings{InitialLimit: 2})
+	_, err := l.Acquire("host1")
+	require.NoError(t, err)
+	done, err := l.Acquire("host1")
+	require.NoError(t, err)
+	_, err = l.Acquire("host2")
+	require.NoError(t, err)
+	_, err = l.Acquire("host1")
+	require.ErrorIs(t, err, ErrLimitExceeded)
+	require.Equal(t, Stats{Limit: 2, InFlight: 2, Rejected: 1}, l.Stats("host1"))
+	done(Success, 0)
+	_, err = l.Acquire("host1")
+	require.NoError(t, err)
+}
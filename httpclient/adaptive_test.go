@@ -0,0 +1,106 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tiagomelo/go-retryable-httpclient/httpclient/adaptive"
+	"github.com/tiagomelo/go-retryable-httpclient/httpclient/retry/policies"
+)
+
+func TestWithAdaptiveConcurrency(t *testing.T) {
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer svr.Close()
+	limiter := adaptive.New(adaptive.Settings{
+		InitialLimit: 4,
+		BackoffRatio: 0.5,
+	})
+	client := New(
+		WithMaxRetries(1),
+		WithCheckRetryPolicy(policies.StatusCodes(http.StatusServiceUnavailable)),
+		WithAdaptiveConcurrency(limiter),
+	)
+	req, err := NewRequest(context.TODO(), http.MethodGet, svr.URL)
+	require.NoError(t, err)
+	_, err = client.SendRequest(req)
+	require.NotNil(t, err)
+	// Both attempts shrink the limit.
+	key := svr.Listener.Addr().String()
+	require.Equal(t, adaptive.Stats{Limit: 1}, limiter.Stats(key))
+	// The limit is taken by a request in flight.
+	done, err := limiter.Acquire(key)
+	require.NoError(t, err)
+	defer done(adaptive.Ignored, 0)
+	req, err = NewRequest(context.TODO(), http.MethodGet, svr.URL)
+	require.NoError(t, err)
+	_, err = client.SendRequest(req)
+	require.NotNil(t, err)
+	require.True(t, strings.Contains(err.Error(), adaptive.ErrLimitExceeded.Error()))
+}
+
+func TestWithAdaptiveConcurrencyMeasuresLatencyUntilHeaders(t *testing.T) {
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer svr.Close()
+	limiter := adaptive.New(adaptive.Settings{
+		InitialLimit:     2,
+		BackoffRatio:     0.5,
+		LatencyThreshold: 50 * time.Millisecond,
+	})
+	client := New(WithAdaptiveConcurrency(limiter))
+	req, err := NewRequest(context.TODO(), http.MethodGet, svr.URL)
+	require.NoError(t, err)
+	resp, err := client.SendRequest(req)
+	require.NoError(t, err)
+	key := svr.Listener.Addr().String()
+	// The attempt stays in flight until its body is closed.
+	require.Equal(t, adaptive.Stats{Limit: 2, InFlight: 1}, limiter.Stats(key))
+	// A slow consumer doesn't make the attempt slow.
+	time.Sleep(100 * time.Millisecond)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, adaptive.Stats{Limit: 3}, limiter.Stats(key))
+}
+
+func TestWithAdaptiveConcurrencyShrinksOnDeadlineExceeded(t *testing.T) {
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer svr.Close()
+	limiter := adaptive.New(adaptive.Settings{
+		InitialLimit: 4,
+		BackoffRatio: 0.5,
+	})
+	client := New(WithAdaptiveConcurrency(limiter))
+	ctx, cancel := context.WithTimeout(context.TODO(), 20*time.Millisecond)
+	defer cancel()
+	req, err := NewRequest(ctx, http.MethodGet, svr.URL)
+	require.NoError(t, err)
+	_, err = client.SendRequest(req)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Equal(t, adaptive.Stats{Limit: 2}, limiter.Stats(svr.Listener.Addr().String()))
+}
+
+func TestWithAdaptiveConcurrencyIgnoresCanceledAttempts(t *testing.T) {
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer svr.Close()
+	limiter := adaptive.New(adaptive.Settings{
+		InitialLimit: 4,
+		BackoffRatio: 0.5,
+	})
+	client := New(WithAdaptiveConcurrency(limiter))
+	ctx, cancel := context.WithCancel(context.TODO())
+	time.AfterFunc(20*time.Millisecond, cancel)
+	req, err := NewRequest(ctx, http.MethodGet, svr.URL)
+	require.NoError(t, err)
+	_, err = client.SendRequest(req)
+	require.ErrorIs(t, err, context.Canceled)
+	require.Equal(t, adaptive.Stats{Limit: 4}, limiter.Stats(svr.Listener.Addr().String()))
+}
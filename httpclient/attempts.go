@@ -0,0 +1,93 @@
+package httpclient
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+)
+
+// attemptLog records the outcome of every attempt of a single call.
+type attemptLog struct {
+	errs      []error
+	retryable bool
+	last      *http.Response
+}
+
+// checkRetry wraps the given check retry policy, recording
+// the outcome of every attempt of the given request, along with
+// whether the policy deemed it retryable.
+func (l *attemptLog) checkRetry(req *http.Request, checkRetry retryablehttp.CheckRetry) retryablehttp.CheckRetry {
+	return func(ctx context.Context, resp *http.Response, err error) (bool, error) {
+		retry, checkErr := checkRetry(ctx, resp, err)
+		l.errs = append(l.errs, attemptError(req, resp, err))
+		l.retryable = retry
+		l.last = resp
+		return retry, checkErr
+	}
+}
+
+// attemptError returns the error of an attempt: the error it got,
+// an *HttpError for an unsuccessful response or nil otherwise.
+func attemptError(req *http.Request, resp *http.Response, err error) error {
+	if err != nil {
+		return err
+	}
+	if resp != nil && resp.StatusCode >= http.StatusBadRequest {
+		return &HttpError{
+			Url:        req.URL.String(),
+			Method:     req.Method,
+			StatusCode: resp.StatusCode,
+		}
+	}
+	return nil
+}
+
+// annotate adds the details of the call to the given error,
+// if it is an *HttpError. When giving up retrying with a successful
+// response, the response is discarded, so the status code and headers
+// are taken from the response of the last attempt, if any.
+func (l *attemptLog) annotate(err error, req *http.Request, resp *http.Response, start time.Time) {
+	httpErr, ok := err.(*HttpError)
+	if !ok {
+		return
+	}
+	httpErr.Method = req.Method
+	if resp == nil && l.last != nil {
+		resp = l.last
+		httpErr.StatusCode = resp.StatusCode
+	}
+	if resp != nil {
+		httpErr.Header = resp.Header
+	}
+	httpErr.Attempts = len(l.errs)
+	httpErr.AttemptErrors = l.errs
+	httpErr.Elapsed = now().Sub(start)
+	httpErr.Retryable = l.retryable
+}
+
+// keepLastResponse returns the error handler called when giving up
+// retrying the given request. Unlike the default one, it returns the
+// unsuccessful response of the last attempt along with the error, so
+// that its body is decoded into the HttpError. The error reads as the
+// default one does.
+func keepLastResponse(req *http.Request) retryablehttp.ErrorHandler {
+	return func(resp *http.Response, err error, numTries int) (*http.Response, error) {
+		if resp != nil && resp.StatusCode < http.StatusBadRequest {
+			// Nothing to decode, the response is drained
+			// so that the connection can be reused.
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+			resp = nil
+		}
+		if err == nil {
+			return resp, fmt.Errorf("%s %s giving up after %d attempt(s)",
+				req.Method, req.URL, numTries)
+		}
+		return resp, fmt.Errorf("%s %s giving up after %d attempt(s): %w",
+			req.Method, req.URL, numTries, err)
+	}
+}
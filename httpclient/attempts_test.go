@@ -0,0 +1,89 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tiagomelo/go-retryable-httpclient/httpclient/retry/policies"
+)
+
+func TestHttpErrorDetails(t *testing.T) {
+	testCases := []struct {
+		name              string
+		statusCode        int
+		expectedAttempts  int
+		expectedRetryable bool
+	}{
+		{
+			name:              "gave up retrying",
+			statusCode:        http.StatusServiceUnavailable,
+			expectedAttempts:  3,
+			expectedRetryable: true,
+		},
+		{
+			name:             "not retried",
+			statusCode:       http.StatusBadRequest,
+			expectedAttempts: 1,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("X-Request-Id", "some id")
+				w.WriteHeader(tc.statusCode)
+			}))
+			defer svr.Close()
+			client := New(
+				WithMaxRetries(2),
+				WithRetryWaitMin(time.Millisecond),
+				WithRetryWaitMax(time.Millisecond),
+				WithCheckRetryPolicy(policies.StatusCodes(http.StatusServiceUnavailable)),
+			)
+			req, err := NewRequest(context.TODO(), http.MethodDelete, svr.URL)
+			require.NoError(t, err)
+			_, err = client.SendRequest(req)
+			require.NotNil(t, err)
+			var httpErr *HttpError
+			require.True(t, errors.As(err, &httpErr))
+			require.Equal(t, http.MethodDelete, httpErr.Method)
+			require.Equal(t, tc.statusCode, httpErr.StatusCode)
+			require.Equal(t, "some id", httpErr.Header.Get("X-Request-Id"))
+			require.Equal(t, tc.expectedAttempts, httpErr.Attempts)
+			require.Len(t, httpErr.AttemptErrors, tc.expectedAttempts)
+			for _, attemptErr := range httpErr.AttemptErrors {
+				require.True(t, errors.Is(attemptErr, &HttpError{StatusCode: tc.statusCode}))
+			}
+			require.Equal(t, tc.expectedRetryable, httpErr.Retryable)
+			require.Greater(t, httpErr.Elapsed, time.Duration(0))
+		})
+	}
+}
+
+func TestHttpErrorDetailsWithoutResponse(t *testing.T) {
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	svr.Close()
+	client := New(
+		WithMaxRetries(1),
+		WithRetryWaitMin(time.Millisecond),
+		WithRetryWaitMax(time.Millisecond),
+		WithCheckRetryPolicy(policies.NetworkErrors),
+	)
+	req, err := NewRequest(context.TODO(), http.MethodGet, svr.URL)
+	require.NoError(t, err)
+	_, err = client.SendRequest(req)
+	require.NotNil(t, err)
+	var httpErr *HttpError
+	require.True(t, errors.As(err, &httpErr))
+	require.Equal(t, http.MethodGet, httpErr.Method)
+	require.Nil(t, httpErr.Header)
+	require.Equal(t, 2, httpErr.Attempts)
+	for _, attemptErr := range httpErr.AttemptErrors {
+		require.True(t, policies.IsNetworkError(attemptErr))
+	}
+	require.True(t, httpErr.Retryable)
+}
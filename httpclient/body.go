@@ -0,0 +1,85 @@
+package httpclient
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/pkg/errors"
+)
+
+// ErrBodyNotRewindable is returned when a request that may be retried
+// carries a body that cannot be read again from the start.
+var ErrBodyNotRewindable = errors.New("request body cannot be rewound for retries")
+
+// rewindableBody returns a function that yields a fresh reader
+// over the request body for every attempt. A nil function is
+// returned when there is nothing to rewind, in which case the
+// body is sent as is.
+func rewindableBody(req *http.Request, mayRetry bool) (retryablehttp.ReaderFunc, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	if req.GetBody != nil {
+		return func() (io.Reader, error) {
+			return req.GetBody()
+		}, nil
+	}
+	if seeker, isSeeker := req.Body.(io.ReadSeeker); isSeeker {
+		return seekerBody(seeker), nil
+	}
+	if mayRetry {
+		return nil, ErrBodyNotRewindable
+	}
+	return nil, nil
+}
+
+// isRewindable reports whether the body of the given
+// request, if any, can be read again from the start.
+func isRewindable(req *http.Request) bool {
+	if req.Body == nil || req.Body == http.NoBody || req.GetBody != nil {
+		return true
+	}
+	_, isSeeker := req.Body.(io.ReadSeeker)
+	return isSeeker
+}
+
+// seekerBody rewinds the given seeker before every attempt.
+// The returned reader hides Close, since the seeker is owned
+// by the caller and must survive between attempts.
+func seekerBody(seeker io.ReadSeeker) retryablehttp.ReaderFunc {
+	return func() (io.Reader, error) {
+		if _, err := seeker.Seek(0, io.SeekStart); err != nil {
+			return nil, errors.Wrap(err, "rewinding request body")
+		}
+		return struct{ io.Reader }{seeker}, nil
+	}
+}
+
+// newRetryableRequest wraps the given request so that
+// its body is replayed on every attempt.
+func newRetryableRequest(req *http.Request, mayRetry bool) (*retryablehttp.Request, error) {
+	bodyReader, err := rewindableBody(req, mayRetry)
+	if err != nil {
+		return nil, err
+	}
+	retryableReq := &retryablehttp.Request{Request: req}
+	if bodyReader == nil {
+		return retryableReq, nil
+	}
+	// SetBody recomputes the content length from the reader,
+	// which loses it for readers that don't expose their length.
+	contentLength := req.ContentLength
+	if err := retryableReq.SetBody(bodyReader); err != nil {
+		return nil, errors.Wrap(err, "reading request body")
+	}
+	retryableReq.ContentLength = contentLength
+	// The original body must not be consumed or closed by
+	// anyone else, e.g. the request dump, before the first attempt.
+	body, err := bodyReader()
+	if err != nil {
+		return nil, errors.Wrap(err, "reading request body")
+	}
+	req.Body = io.NopCloser(body)
+	return retryableReq, nil
+}
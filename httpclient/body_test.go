@@ -0,0 +1,225 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type nonRewindableBody struct {
+	io.Reader
+}
+
+func (b *nonRewindableBody) Close() error {
+	return nil
+}
+
+func TestRetriedRequestResendsBody(t *testing.T) {
+	const payload = `{"name":"Steve Harris","email":"steve@ironmaiden.com"}`
+	file := filepath.Join(t.TempDir(), "payload.json")
+	require.NoError(t, os.WriteFile(file, []byte(payload), 0o600))
+	testCases := []struct {
+		name       string
+		newRequest func(url string) (*http.Request, error)
+	}{
+		{
+			name: "json request",
+			newRequest: func(url string) (*http.Request, error) {
+				return NewJsonRequest(context.TODO(), http.MethodPost, url, payload)
+			},
+		},
+		{
+			name: "bytes body",
+			newRequest: func(url string) (*http.Request, error) {
+				return NewRequestWithBody(context.TODO(), http.MethodPut, url, []byte(payload))
+			},
+		},
+		{
+			name: "string body",
+			newRequest: func(url string) (*http.Request, error) {
+				return NewRequestWithBody(context.TODO(), http.MethodPut, url, payload)
+			},
+		},
+		{
+			name: "read seeker body",
+			newRequest: func(url string) (*http.Request, error) {
+				f, err := os.Open(file)
+				if err != nil {
+					return nil, err
+				}
+				t.Cleanup(func() { f.Close() })
+				return NewRequestWithBody(context.TODO(), http.MethodPut, url, f)
+			},
+		},
+		{
+			name: "read seeker as raw request body",
+			newRequest: func(url string) (*http.Request, error) {
+				f, err := os.Open(file)
+				if err != nil {
+					return nil, err
+				}
+				t.Cleanup(func() { f.Close() })
+				req, err := http.NewRequest(http.MethodPut, url, nil)
+				if err != nil {
+					return nil, err
+				}
+				req.Body = f
+				return req, nil
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				mu     sync.Mutex
+				bodies []string
+			)
+			svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				b, err := io.ReadAll(r.Body)
+				require.NoError(t, err)
+				mu.Lock()
+				defer mu.Unlock()
+				bodies = append(bodies, string(b))
+				if len(bodies) < 3 {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer svr.Close()
+			client := New(
+				WithMaxRetries(2),
+				WithRetryWaitMin(time.Millisecond),
+				WithRetryWaitMax(time.Millisecond),
+				WithCheckRetryPolicy(retryOnServiceUnavailable),
+				WithRequestDumpLogger(func(dump []byte) {}, true),
+			)
+			req, err := tc.newRequest(svr.URL)
+			require.NoError(t, err)
+			resp, err := client.SendRequest(req)
+			require.NoError(t, err)
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+			require.Equal(t, []string{payload, payload, payload}, bodies)
+		})
+	}
+}
+
+func TestNonRewindableBody(t *testing.T) {
+	testCases := []struct {
+		name          string
+		options       []Option
+		expectedError error
+	}{
+		{
+			name:          "retries enabled",
+			options:       []Option{WithMaxRetries(1)},
+			expectedError: ErrBodyNotRewindable,
+		},
+		{
+			name: "retries disabled",
+		},
+	}
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.Equal(t, "some payload", string(b))
+	}))
+	defer svr.Close()
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := New(tc.options...)
+			req, err := http.NewRequest(http.MethodPost, svr.URL, nil)
+			require.NoError(t, err)
+			req.Body = &nonRewindableBody{strings.NewReader("some payload")}
+			_, err = client.SendRequest(req)
+			if err != nil {
+				checkIfErrorIsExpected(t, err, tc.expectedError)
+				require.ErrorIs(t, err, &HttpError{Err: tc.expectedError})
+			} else {
+				checkIfErrorIsNotExpected(t, err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestNewRequestWithBody(t *testing.T) {
+	testCases := []struct {
+		name            string
+		payload         any
+		mockNewRequest  newRequestMock
+		expectedReqBody string
+		expectedError   error
+	}{
+		{
+			name: "without payload",
+		},
+		{
+			name:            "bytes payload",
+			payload:         []byte("some payload"),
+			expectedReqBody: "some payload",
+		},
+		{
+			name:            "string payload",
+			payload:         "some payload",
+			expectedReqBody: "some payload",
+		},
+		{
+			name:            "read seeker payload",
+			payload:         bytes.NewReader([]byte("some payload")),
+			expectedReqBody: "some payload",
+		},
+		{
+			name:          "unsupported payload",
+			payload:       42,
+			expectedError: errors.New("unsupported request body type int"),
+		},
+		{
+			name:    "error creating request",
+			payload: "some payload",
+			mockNewRequest: func(ctx context.Context, method,
+				url string, body io.Reader) (*http.Request, error) {
+				return nil, errors.New("random error")
+			},
+			expectedError: errors.New("creating request: random error"),
+		},
+	}
+	defer handleNewRequestMock(nil, originalNewRequestWithContext)
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			handleNewRequestMock(tc.mockNewRequest, originalNewRequestWithContext)
+			req, err := NewRequestWithBody(context.TODO(), http.MethodPost, "url", tc.payload)
+			if err != nil {
+				checkIfErrorIsExpected(t, err, tc.expectedError)
+				require.Equal(t, tc.expectedError.Error(), err.Error())
+				return
+			}
+			checkIfErrorIsNotExpected(t, err, tc.expectedError)
+			if tc.expectedReqBody == "" {
+				require.Nil(t, req.GetBody)
+				return
+			}
+			for i := 0; i < 2; i++ {
+				body, err := req.GetBody()
+				require.NoError(t, err)
+				b, err := io.ReadAll(body)
+				require.NoError(t, err)
+				require.Equal(t, tc.expectedReqBody, string(b))
+			}
+		})
+	}
+}
+
+func retryOnServiceUnavailable(ctx context.Context, resp *http.Response, err error) (bool, error) {
+	return resp != nil && resp.StatusCode == http.StatusServiceUnavailable, err
+}
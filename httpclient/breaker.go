@@ -0,0 +1,64 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/tiagomelo/go-retryable-httpclient/httpclient/adaptive"
+	"github.com/tiagomelo/go-retryable-httpclient/httpclient/bulkhead"
+	"github.com/tiagomelo/go-retryable-httpclient/httpclient/circuitbreaker"
+	"github.com/tiagomelo/go-retryable-httpclient/httpclient/ratelimit"
+)
+
+// circuitBreakerKey returns the key of the circuit
+// the given request goes through.
+func (c *Client) circuitBreakerKey(req *http.Request) string {
+	if c.breakerKey != nil {
+		return c.breakerKey(req)
+	}
+	return req.URL.Host
+}
+
+// isRejected reports whether the given error comes from an attempt
+// rejected by the client itself, before reaching the upstream.
+func isRejected(err error) bool {
+	var rejectedErr *bulkhead.RejectedError
+	return errors.As(err, &rejectedErr) ||
+		errors.Is(err, ratelimit.ErrWaitExceedsDeadline) ||
+		errors.Is(err, adaptive.ErrLimitExceeded)
+}
+
+// breakerCheckRetry wraps the given check retry policy, recording
+// the outcome of every attempt in the circuit breaker. An attempt
+// fails when the policy would retry it or when it got an error,
+// e.g. because its deadline was exceeded. Attempts that were
+// canceled or rejected by the client itself, e.g. by the bulkhead,
+// say nothing about the upstream, so they aren't recorded.
+// Retries stop as soon as the circuit opens.
+func breakerCheckRetry(breaker *circuitbreaker.CircuitBreaker, key string,
+	checkRetry retryablehttp.CheckRetry) retryablehttp.CheckRetry {
+	return func(ctx context.Context, resp *http.Response, err error) (bool, error) {
+		retry, checkErr := checkRetry(ctx, resp, err)
+		if isCanceled(ctx, err) || isRejected(err) {
+			breaker.Cancel(key)
+			return retry, checkErr
+		}
+		breaker.Record(key, !retry && err == nil)
+		if retry && breaker.State(key) == circuitbreaker.Open {
+			return false, circuitbreaker.ErrOpen
+		}
+		return retry, checkErr
+	}
+}
+
+// releaseUnrecordedProbe releases the request allowed through the
+// circuit breaker when none of its attempts reached the check retry
+// policy, e.g. because its body couldn't be read, so that a half-open
+// circuit doesn't wait forever for the outcome of its probe.
+func (c *Client) releaseUnrecordedProbe(req *http.Request, log *attemptLog) {
+	if c.breaker != nil && len(log.errs) == 0 {
+		c.breaker.Cancel(c.circuitBreakerKey(req))
+	}
+}
@@ -0,0 +1,224 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/stretchr/testify/require"
+	"github.com/tiagomelo/go-retryable-httpclient/httpclient/adaptive"
+	"github.com/tiagomelo/go-retryable-httpclient/httpclient/bulkhead"
+	"github.com/tiagomelo/go-retryable-httpclient/httpclient/circuitbreaker"
+	"github.com/tiagomelo/go-retryable-httpclient/httpclient/retry/policies"
+)
+
+func TestWithCircuitBreaker(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		attempts int
+		status   = http.StatusServiceUnavailable
+	)
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		attempts++
+		w.WriteHeader(status)
+	}))
+	defer svr.Close()
+	var states []circuitbreaker.State
+	breaker := circuitbreaker.New(circuitbreaker.Settings{
+		FailureThreshold: 3,
+		OpenTimeout:      50 * time.Millisecond,
+		OnStateChange: func(key string, from, to circuitbreaker.State) {
+			states = append(states, to)
+		},
+	})
+	client := New(
+		WithMaxRetries(10),
+		WithCheckRetryPolicy(policies.StatusCodes(http.StatusServiceUnavailable)),
+		WithCircuitBreaker(breaker),
+	)
+	req, err := NewRequest(context.TODO(), http.MethodGet, svr.URL)
+	require.NoError(t, err)
+
+	// Retries stop as soon as the circuit opens.
+	_, err = client.SendRequest(req)
+	require.NotNil(t, err)
+	require.True(t, strings.Contains(err.Error(), circuitbreaker.ErrOpen.Error()))
+	require.Equal(t, 3, attempts)
+
+	// Requests are short-circuited while the circuit is open.
+	_, err = client.SendRequest(req)
+	require.ErrorIs(t, err, &HttpError{Err: circuitbreaker.ErrOpen})
+	require.Equal(t, 3, attempts)
+
+	// A successful probe closes the circuit.
+	time.Sleep(50 * time.Millisecond)
+	mu.Lock()
+	status = http.StatusOK
+	mu.Unlock()
+	_, err = client.SendRequest(req)
+	require.NoError(t, err)
+	require.Equal(t, 4, attempts)
+	require.Equal(t, []circuitbreaker.State{
+		circuitbreaker.Open,
+		circuitbreaker.HalfOpen,
+		circuitbreaker.Closed,
+	}, states)
+}
+
+func TestWithCircuitBreakerKey(t *testing.T) {
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer svr.Close()
+	breaker := circuitbreaker.New(circuitbreaker.Settings{FailureThreshold: 1})
+	client := New(
+		WithCheckRetryPolicy(policies.StatusCodes(http.StatusServiceUnavailable)),
+		WithCircuitBreaker(breaker),
+		WithCircuitBreakerKey(func(req *http.Request) string {
+			return req.URL.Path
+		}),
+	)
+	req, err := NewRequest(context.TODO(), http.MethodGet, svr.URL+"/some/path")
+	require.NoError(t, err)
+	_, err = client.SendRequest(req)
+	require.NotNil(t, err)
+	require.Equal(t, circuitbreaker.Open, breaker.State("/some/path"))
+	req, err = NewRequest(context.TODO(), http.MethodGet, svr.URL+"/another/path")
+	require.NoError(t, err)
+	_, err = client.SendRequest(req)
+	require.NotErrorIs(t, err, &HttpError{Err: circuitbreaker.ErrOpen})
+}
+
+func TestWithCircuitBreakerReleasesUnrecordedProbes(t *testing.T) {
+	breaker := circuitbreaker.New(circuitbreaker.Settings{
+		FailureThreshold: 1,
+		OpenTimeout:      time.Millisecond,
+	})
+	breaker.Record("localhost", false)
+	time.Sleep(time.Millisecond)
+	require.Equal(t, circuitbreaker.HalfOpen, breaker.State("localhost"))
+	originalRetryableHttpClientDo := retryableHttpClientDo
+	defer func() { retryableHttpClientDo = originalRetryableHttpClientDo }()
+	// Fails without any attempt reaching the check retry policy.
+	retryableHttpClientDo = func(retryableHttpClient *retryablehttp.Client,
+		req *retryablehttp.Request) (*http.Response, error) {
+		return nil, errors.New("reading request body")
+	}
+	client := New(WithCircuitBreaker(breaker))
+	req, err := NewRequest(context.TODO(), http.MethodGet, "http://localhost/some/path")
+	require.NoError(t, err)
+	for i := 0; i < 2; i++ {
+		_, err = client.SendRequest(req)
+		require.NotErrorIs(t, err, &HttpError{Err: circuitbreaker.ErrOpen})
+	}
+	require.Equal(t, circuitbreaker.HalfOpen, breaker.State("localhost"))
+}
+
+func TestWithCircuitBreakerRecordsDeadlinesExceeded(t *testing.T) {
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer svr.Close()
+	breaker := circuitbreaker.New(circuitbreaker.Settings{FailureThreshold: 2})
+	client := New(WithCircuitBreaker(breaker))
+	key := svr.Listener.Addr().String()
+	for i := 0; i < 2; i++ {
+		ctx, cancel := context.WithTimeout(context.TODO(), 20*time.Millisecond)
+		req, err := NewRequest(ctx, http.MethodGet, svr.URL)
+		require.NoError(t, err)
+		_, err = client.SendRequest(req)
+		cancel()
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	}
+	require.Equal(t, circuitbreaker.Open, breaker.State(key))
+}
+
+func TestWithCircuitBreakerIgnoresCanceledAttempts(t *testing.T) {
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer svr.Close()
+	breaker := circuitbreaker.New(circuitbreaker.Settings{FailureThreshold: 1})
+	client := New(WithCircuitBreaker(breaker))
+	ctx, cancel := context.WithCancel(context.TODO())
+	time.AfterFunc(20*time.Millisecond, cancel)
+	req, err := NewRequest(ctx, http.MethodGet, svr.URL)
+	require.NoError(t, err)
+	_, err = client.SendRequest(req)
+	require.ErrorIs(t, err, context.Canceled)
+	require.Equal(t, circuitbreaker.Closed, breaker.State(svr.Listener.Addr().String()))
+}
+
+func TestWithCircuitBreakerIgnoresClientSideRejections(t *testing.T) {
+	testCases := []struct {
+		name    string
+		options []Option
+		ctx     func() (context.Context, context.CancelFunc)
+	}{
+		{
+			name: "bulkhead queue is full",
+			options: []Option{
+				WithBulkhead(bulkhead.New(bulkhead.Settings{MaxConcurrent: 1})),
+			},
+		},
+		{
+			name: "adaptive concurrency limit exceeded",
+			options: []Option{
+				WithAdaptiveConcurrency(adaptive.New(adaptive.Settings{InitialLimit: 1, MaxLimit: 1})),
+			},
+		},
+		{
+			name: "rate limiter wait exceeds deadline",
+			options: []Option{
+				WithRateLimit(0.1, 1),
+			},
+			ctx: func() (context.Context, context.CancelFunc) {
+				return context.WithTimeout(context.TODO(), time.Second)
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			release := make(chan struct{})
+			svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				<-release
+			}))
+			defer svr.Close()
+			breaker := circuitbreaker.New(circuitbreaker.Settings{FailureThreshold: 1})
+			client := New(append(tc.options, WithCircuitBreaker(breaker))...)
+			// Takes the only slot or token.
+			inFlight := make(chan error)
+			go func() {
+				req, err := NewRequest(context.TODO(), http.MethodGet, svr.URL)
+				require.NoError(t, err)
+				resp, err := client.SendRequest(req)
+				if err == nil {
+					resp.Body.Close()
+				}
+				inFlight <- err
+			}()
+			time.Sleep(20 * time.Millisecond)
+			ctx, cancel := context.WithCancel(context.TODO())
+			if tc.ctx != nil {
+				ctx, cancel = tc.ctx()
+			}
+			defer cancel()
+			req, err := NewRequest(ctx, http.MethodGet, svr.URL)
+			require.NoError(t, err)
+			_, err = client.SendRequest(req)
+			require.Error(t, err)
+			require.True(t, isRejected(err))
+			close(release)
+			require.NoError(t, <-inFlight)
+			require.Equal(t, circuitbreaker.Closed, breaker.State(strings.TrimPrefix(svr.URL, "http://")))
+		})
+	}
+}
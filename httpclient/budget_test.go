@@ -0,0 +1,51 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tiagomelo/go-retryable-httpclient/httpclient/retry/budget"
+	"github.com/tiagomelo/go-retryable-httpclient/httpclient/retry/policies"
+)
+
+func TestWithRetryBudget(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		attempts int
+	)
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		attempts++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer svr.Close()
+	retryBudget := budget.New(budget.Settings{
+		Ratio:               0.01,
+		MinRetriesPerSecond: 1,
+		Window:              time.Minute,
+	})
+	client := New(
+		WithMaxRetries(100),
+		WithRetryWaitMin(time.Millisecond),
+		WithRetryWaitMax(time.Millisecond),
+		WithCheckRetryPolicy(policies.StatusCodes(http.StatusServiceUnavailable)),
+		WithRetryBudget(retryBudget),
+	)
+	req, err := NewRequest(context.TODO(), http.MethodGet, svr.URL)
+	require.NoError(t, err)
+	// The budget allows a minute worth of minimum retries.
+	_, err = client.SendRequest(req)
+	require.ErrorIs(t, err, budget.ErrExhausted)
+	require.Equal(t, 61, attempts)
+	// Once exhausted, requests are no longer retried.
+	_, err = client.SendRequest(req)
+	require.ErrorIs(t, err, budget.ErrExhausted)
+	require.Equal(t, 62, attempts)
+	require.Equal(t, budget.Stats{Requests: 2, Retries: 60, Rejected: 2}, retryBudget.Stats())
+}
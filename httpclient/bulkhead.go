@@ -0,0 +1,65 @@
+package httpclient
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/tiagomelo/go-retryable-httpclient/httpclient/bulkhead"
+)
+
+// releaseOnClose releases a request in flight once the body is closed.
+type releaseOnClose struct {
+	io.ReadCloser
+	release func()
+}
+
+// Close closes the body and releases the request.
+func (b *releaseOnClose) Close() error {
+	defer b.release()
+	return b.ReadCloser.Close()
+}
+
+// bulkheadTransport is an http.RoundTripper that goes through
+// the client bulkhead before every attempt, including the
+// retries. An attempt stays in flight until its response
+// body is closed.
+type bulkheadTransport struct {
+	next     http.RoundTripper
+	bulkhead *bulkhead.Bulkhead
+}
+
+// RoundTrip waits for its turn in the bulkhead and sends the request.
+func (t *bulkheadTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	release, err := t.bulkhead.Acquire(req.Context(), req.URL.Host)
+	if err != nil {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, err
+	}
+	resp, err := t.next.RoundTrip(req)
+	if err != nil || resp.Body == nil {
+		release()
+		return resp, err
+	}
+	resp.Body = &releaseOnClose{ReadCloser: resp.Body, release: release}
+	return resp, nil
+}
+
+// bulkheadClient returns a copy of the given http client
+// whose transport goes through the client bulkhead.
+func (c *Client) bulkheadClient(httpClient *http.Client) *http.Client {
+	if c.bulkhead == nil {
+		return httpClient
+	}
+	next := httpClient.Transport
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	hc := *httpClient
+	hc.Transport = &bulkheadTransport{
+		next:     next,
+		bulkhead: c.bulkhead,
+	}
+	return &hc
+}
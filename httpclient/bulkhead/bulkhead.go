@@ -0,0 +1,208 @@
+// Package bulkhead provides a bulkhead, which bounds the number
+// of requests in flight to each host, along with the number of
+// requests waiting for their turn, so that a slow upstream can't
+// pile up an unbounded number of goroutines.
+// It can be used with httpclient.WithBulkhead.
+package bulkhead
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrQueueFull is returned when a request is rejected
+	// because too many requests are already waiting.
+	ErrQueueFull = errors.New("bulkhead queue is full")
+	// ErrQueueTimeout is returned when a request is rejected
+	// because it waited longer than the queue timeout.
+	ErrQueueTimeout = errors.New("bulkhead queue timeout")
+)
+
+// Default settings.
+const (
+	DefaultMaxConcurrent = 10
+)
+
+// RejectedError is returned when the bulkhead rejects a request.
+// Err is ErrQueueFull, ErrQueueTimeout or the error of the
+// request context when it is done while waiting.
+type RejectedError struct {
+	Key string
+	Err error
+}
+
+// Error returns the error message. It implements the error interface.
+func (e *RejectedError) Error() string {
+	return fmt.Sprintf("bulkhead rejected request to %v: %v", e.Key, e.Err)
+}
+
+// Unwrap returns the reason why the request was rejected.
+func (e *RejectedError) Unwrap() error {
+	return e.Err
+}
+
+// Settings configures a Bulkhead.
+type Settings struct {
+	// MaxConcurrent is the number of requests allowed in flight
+	// for each key. Defaults to DefaultMaxConcurrent.
+	MaxConcurrent int
+	// MaxQueue is the number of requests allowed to wait for their
+	// turn for each key. Requests are rejected right away when it
+	// is zero and no more requests are allowed in flight.
+	MaxQueue int
+	// QueueTimeout is how long a request may wait for its turn.
+	// When it is zero, requests wait until their context is done.
+	QueueTimeout time.Duration
+}
+
+// Stats holds the state of the compartment for a single key.
+type Stats struct {
+	// InFlight is the number of requests in flight.
+	InFlight int
+	// Queued is the number of requests waiting for their turn.
+	Queued int
+	// Rejected is the number of requests rejected since
+	// the Bulkhead was created.
+	Rejected int
+}
+
+// compartment holds the state of a single key. Waiters are
+// served in order, each one being handed over the turn of
+// a request that is done.
+type compartment struct {
+	inFlight int
+	waiters  []chan struct{}
+	rejected int
+}
+
+// Bulkhead keeps one compartment per key, e.g. per host.
+// It is safe for concurrent use.
+type Bulkhead struct {
+	settings     Settings
+	mu           sync.Mutex
+	compartments map[string]*compartment
+}
+
+// New returns a new Bulkhead.
+func New(settings Settings) *Bulkhead {
+	if settings.MaxConcurrent <= 0 {
+		settings.MaxConcurrent = DefaultMaxConcurrent
+	}
+	if settings.MaxQueue < 0 {
+		settings.MaxQueue = 0
+	}
+	return &Bulkhead{
+		settings:     settings,
+		compartments: make(map[string]*compartment),
+	}
+}
+
+// compartment returns the compartment for the given key.
+// It must be called with the lock held.
+func (b *Bulkhead) compartment(key string) *compartment {
+	c, ok := b.compartments[key]
+	if !ok {
+		c = new(compartment)
+		b.compartments[key] = c
+	}
+	return c
+}
+
+// Acquire blocks until a request for the given key is allowed in
+// flight, returning a function that must be called once it is done.
+// It returns a *RejectedError when the queue is full, when the queue
+// timeout elapses or when ctx is done while waiting.
+func (b *Bulkhead) Acquire(ctx context.Context, key string) (func(), error) {
+	b.mu.Lock()
+	c := b.compartment(key)
+	if c.inFlight < b.settings.MaxConcurrent && len(c.waiters) == 0 {
+		c.inFlight++
+		b.mu.Unlock()
+		return b.releaser(key), nil
+	}
+	if len(c.waiters) >= b.settings.MaxQueue {
+		c.rejected++
+		b.mu.Unlock()
+		return nil, &RejectedError{Key: key, Err: ErrQueueFull}
+	}
+	turn := make(chan struct{})
+	c.waiters = append(c.waiters, turn)
+	b.mu.Unlock()
+	var timeout <-chan time.Time
+	if b.settings.QueueTimeout > 0 {
+		timer := time.NewTimer(b.settings.QueueTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	var err error
+	select {
+	case <-turn:
+		return b.releaser(key), nil
+	case <-timeout:
+		err = ErrQueueTimeout
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	return nil, b.leave(key, turn, err)
+}
+
+// leave removes the given waiter from the queue of the given key,
+// returning the error it is rejected with. When its turn came
+// meanwhile, the turn is handed over to the next waiter.
+func (b *Bulkhead) leave(key string, turn chan struct{}, err error) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c := b.compartment(key)
+	c.rejected++
+	for i, waiter := range c.waiters {
+		if waiter == turn {
+			c.waiters = append(c.waiters[:i], c.waiters[i+1:]...)
+			return &RejectedError{Key: key, Err: err}
+		}
+	}
+	b.release(c)
+	return &RejectedError{Key: key, Err: err}
+}
+
+// releaser returns a function that releases a request in flight
+// for the given key. Calling it more than once has no effect.
+func (b *Bulkhead) releaser(key string) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			b.release(b.compartment(key))
+		})
+	}
+}
+
+// release hands over the turn of a request that is done
+// to the next waiter, if any.
+// It must be called with the lock held.
+func (b *Bulkhead) release(c *compartment) {
+	if len(c.waiters) == 0 {
+		c.inFlight--
+		return
+	}
+	turn := c.waiters[0]
+	c.waiters = c.waiters[1:]
+	close(turn)
+}
+
+// Stats returns the state of the compartment for the given key.
+func (b *Bulkhead) Stats(key string) Stats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c := b.compartment(key)
+	return Stats{
+		InFlight: c.inFlight,
+		Queued:   len(c.waiters),
+		Rejected: c.rejected,
+	}
+}
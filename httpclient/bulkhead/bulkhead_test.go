@@ -0,0 +1,110 @@
+package bulkhead
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAcquire(t *testing.T) {
+	testCases := []struct {
+		name          string
+		settings      Settings
+		ctx           func() (context.Context, context.CancelFunc)
+		expectedError error
+	}{
+		{
+			name:     "waits for its turn",
+			settings: Settings{MaxConcurrent: 1, MaxQueue: 1},
+			ctx: func() (context.Context, context.CancelFunc) {
+				return context.WithCancel(context.TODO())
+			},
+		},
+		{
+			name:     "queue full",
+			settings: Settings{MaxConcurrent: 1},
+			ctx: func() (context.Context, context.CancelFunc) {
+				return context.WithCancel(context.TODO())
+			},
+			expectedError: ErrQueueFull,
+		},
+		{
+			name:     "queue timeout",
+			settings: Settings{MaxConcurrent: 1, MaxQueue: 1, QueueTimeout: 10 * time.Millisecond},
+			ctx: func() (context.Context, context.CancelFunc) {
+				return context.WithCancel(context.TODO())
+			},
+			expectedError: ErrQueueTimeout,
+		},
+		{
+			name:     "canceled while waiting",
+			settings: Settings{MaxConcurrent: 1, MaxQueue: 1},
+			ctx: func() (context.Context, context.CancelFunc) {
+				ctx, cancel := context.WithCancel(context.TODO())
+				time.AfterFunc(10*time.Millisecond, cancel)
+				return ctx, cancel
+			},
+			expectedError: context.Canceled,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			b := New(tc.settings)
+			release, err := b.Acquire(context.TODO(), "host")
+			require.NoError(t, err)
+			time.AfterFunc(50*time.Millisecond, release)
+			ctx, cancel := tc.ctx()
+			defer cancel()
+			release, err = b.Acquire(ctx, "host")
+			if tc.expectedError != nil {
+				require.NotNil(t, err)
+				var rejectedErr *RejectedError
+				require.True(t, errors.As(err, &rejectedErr))
+				require.Equal(t, "host", rejectedErr.Key)
+				require.True(t, errors.Is(err, tc.expectedError))
+				require.Equal(t, 1, b.Stats("host").Rejected)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, Stats{InFlight: 1}, b.Stats("host"))
+			release()
+			release()
+			require.Equal(t, Stats{}, b.Stats("host"))
+		})
+	}
+}
+
+func TestAcquireIsKeyed(t *testing.T) {
+	b := New(Settings{MaxConcurrent: 1})
+	_, err := b.Acquire(context.TODO(), "host1")
+	require.NoError(t, err)
+	_, err = b.Acquire(context.TODO(), "host2")
+	require.NoError(t, err)
+	_, err = b.Acquire(context.TODO(), "host1")
+	require.True(t, errors.Is(err, ErrQueueFull))
+}
+
+func TestAcquireServesWaitersInOrder(t *testing.T) {
+	b := New(Settings{MaxConcurrent: 1, MaxQueue: 2})
+	release, err := b.Acquire(context.TODO(), "host")
+	require.NoError(t, err)
+	order := make(chan int, 2)
+	for i := 1; i <= 2; i++ {
+		i := i
+		go func() {
+			release, err := b.Acquire(context.TODO(), "host")
+			require.NoError(t, err)
+			order <- i
+			release()
+		}()
+		require.Eventually(t, func() bool {
+			return b.Stats("host").Queued == i
+		}, time.Second, time.Millisecond)
+	}
+	release()
+	require.Equal(t, 1, <-order)
+	require.Equal(t, 2, <-order)
+}
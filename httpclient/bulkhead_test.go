@@ -0,0 +1,51 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tiagomelo/go-retryable-httpclient/httpclient/bulkhead"
+)
+
+func TestWithBulkhead(t *testing.T) {
+	unblock := make(chan struct{})
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-unblock
+	}))
+	defer svr.Close()
+	b := bulkhead.New(bulkhead.Settings{
+		MaxConcurrent: 1,
+		MaxQueue:      1,
+		QueueTimeout:  20 * time.Millisecond,
+	})
+	client := New(WithBulkhead(b))
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		req, err := NewRequest(context.TODO(), http.MethodGet, svr.URL)
+		require.NoError(t, err)
+		resp, err := client.SendRequest(req)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+	}()
+	require.Eventually(t, func() bool {
+		return b.Stats(svr.Listener.Addr().String()).InFlight == 1
+	}, time.Second, time.Millisecond)
+	req, err := NewRequest(context.TODO(), http.MethodGet, svr.URL)
+	require.NoError(t, err)
+	_, err = client.SendRequest(req)
+	require.NotNil(t, err)
+	var rejectedErr *bulkhead.RejectedError
+	require.True(t, errors.As(err.(*HttpError).Err, &rejectedErr))
+	require.True(t, errors.Is(rejectedErr, bulkhead.ErrQueueTimeout))
+	close(unblock)
+	wg.Wait()
+	require.Equal(t, 0, b.Stats(svr.Listener.Addr().String()).InFlight)
+}
@@ -0,0 +1,240 @@
+// Package circuitbreaker provides a circuit breaker that stops sending
+// requests to an upstream that keeps failing, giving it time to recover.
+// It can be used with httpclient.WithCircuitBreaker.
+package circuitbreaker
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ErrOpen is returned when a request is short-circuited
+// because the circuit is open.
+var ErrOpen = errors.New("circuit breaker is open")
+
+// For ease of unit testing.
+// Declaring these functions as global variables
+// makes it easy to mock them.
+var (
+	now = time.Now
+)
+
+// Default settings.
+const (
+	DefaultFailureThreshold    = 5
+	DefaultOpenTimeout         = 30 * time.Second
+	DefaultHalfOpenMaxRequests = 1
+)
+
+// State represents the state of a circuit.
+type State int
+
+const (
+	// Closed lets requests through, counting consecutive failures.
+	Closed State = iota
+	// Open short-circuits requests until the open timeout elapses.
+	Open
+	// HalfOpen lets a limited number of probe requests through,
+	// closing the circuit when they succeed and opening it again
+	// when any of them fails.
+	HalfOpen
+)
+
+// String returns the name of the state.
+func (s State) String() string {
+	switch s {
+	case Closed:
+		return "closed"
+	case Open:
+		return "open"
+	case HalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// Settings configures a CircuitBreaker.
+type Settings struct {
+	// FailureThreshold is the number of consecutive failures
+	// that opens a closed circuit. Defaults to DefaultFailureThreshold.
+	FailureThreshold int
+	// OpenTimeout is how long a circuit stays open before
+	// letting probe requests through. Defaults to DefaultOpenTimeout.
+	OpenTimeout time.Duration
+	// HalfOpenMaxRequests is the number of probe requests let through,
+	// and of successes needed to close a half-open circuit.
+	// Defaults to DefaultHalfOpenMaxRequests.
+	HalfOpenMaxRequests int
+	// OnStateChange, if provided, is called whenever the
+	// circuit for the given key changes its state.
+	OnStateChange func(key string, from, to State)
+}
+
+// circuit holds the state of the circuit for a single key.
+type circuit struct {
+	state     State
+	failures  int
+	successes int
+	probes    int
+	openedAt  time.Time
+}
+
+// transition represents a change of state of a circuit.
+type transition struct {
+	key      string
+	from, to State
+}
+
+// CircuitBreaker keeps one circuit per key, e.g. per host.
+// It is safe for concurrent use.
+type CircuitBreaker struct {
+	settings Settings
+	mu       sync.Mutex
+	circuits map[string]*circuit
+}
+
+// New returns a new CircuitBreaker.
+func New(settings Settings) *CircuitBreaker {
+	if settings.FailureThreshold <= 0 {
+		settings.FailureThreshold = DefaultFailureThreshold
+	}
+	if settings.OpenTimeout <= 0 {
+		settings.OpenTimeout = DefaultOpenTimeout
+	}
+	if settings.HalfOpenMaxRequests <= 0 {
+		settings.HalfOpenMaxRequests = DefaultHalfOpenMaxRequests
+	}
+	return &CircuitBreaker{
+		settings: settings,
+		circuits: make(map[string]*circuit),
+	}
+}
+
+// circuit returns the circuit for the given key, moving it
+// to half-open when its open timeout has elapsed.
+// It must be called with the lock held.
+func (cb *CircuitBreaker) circuit(key string, transitions *[]transition) *circuit {
+	c, ok := cb.circuits[key]
+	if !ok {
+		c = new(circuit)
+		cb.circuits[key] = c
+	}
+	if c.state == Open && now().Sub(c.openedAt) >= cb.settings.OpenTimeout {
+		cb.setState(key, c, HalfOpen, transitions)
+	}
+	return c
+}
+
+// setState changes the state of the given circuit, resetting its counters.
+// It must be called with the lock held.
+func (cb *CircuitBreaker) setState(key string, c *circuit, state State, transitions *[]transition) {
+	*transitions = append(*transitions, transition{key: key, from: c.state, to: state})
+	c.state = state
+	c.failures, c.successes, c.probes = 0, 0, 0
+	if state == Open {
+		c.openedAt = now()
+	}
+}
+
+// notify calls the state change callback for the given transitions.
+// It must be called without the lock held.
+func (cb *CircuitBreaker) notify(transitions []transition) {
+	if cb.settings.OnStateChange == nil {
+		return
+	}
+	for _, t := range transitions {
+		cb.settings.OnStateChange(t.key, t.from, t.to)
+	}
+}
+
+// Allow returns ErrOpen when a request for the given key must be
+// short-circuited. In half-open state, only a limited number
+// of probe requests are allowed.
+func (cb *CircuitBreaker) Allow(key string) error {
+	var transitions []transition
+	defer func() { cb.notify(transitions) }()
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	c := cb.circuit(key, &transitions)
+	switch c.state {
+	case Open:
+		return ErrOpen
+	case HalfOpen:
+		if c.probes >= cb.settings.HalfOpenMaxRequests {
+			return ErrOpen
+		}
+		c.probes++
+	}
+	return nil
+}
+
+// Record records the outcome of a request for the given key.
+func (cb *CircuitBreaker) Record(key string, success bool) {
+	var transitions []transition
+	defer func() { cb.notify(transitions) }()
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	c := cb.circuit(key, &transitions)
+	c.releaseProbe()
+	if success {
+		cb.recordSuccess(key, c, &transitions)
+		return
+	}
+	cb.recordFailure(key, c, &transitions)
+}
+
+// recordSuccess records a successful request.
+// It must be called with the lock held.
+func (cb *CircuitBreaker) recordSuccess(key string, c *circuit, transitions *[]transition) {
+	c.failures = 0
+	if c.state != HalfOpen {
+		return
+	}
+	c.successes++
+	if c.successes >= cb.settings.HalfOpenMaxRequests {
+		cb.setState(key, c, Closed, transitions)
+	}
+}
+
+// recordFailure records a failed request.
+// It must be called with the lock held.
+func (cb *CircuitBreaker) recordFailure(key string, c *circuit, transitions *[]transition) {
+	switch c.state {
+	case HalfOpen:
+		cb.setState(key, c, Open, transitions)
+	case Closed:
+		c.failures++
+		if c.failures >= cb.settings.FailureThreshold {
+			cb.setState(key, c, Open, transitions)
+		}
+	}
+}
+
+// Cancel releases a request for the given key that was allowed
+// but whose outcome is unknown, e.g. because it was canceled.
+func (cb *CircuitBreaker) Cancel(key string) {
+	var transitions []transition
+	defer func() { cb.notify(transitions) }()
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.circuit(key, &transitions).releaseProbe()
+}
+
+// releaseProbe frees a probe slot of a half-open circuit.
+func (c *circuit) releaseProbe() {
+	if c.state == HalfOpen && c.probes > 0 {
+		c.probes--
+	}
+}
+
+// State returns the state of the circuit for the given key.
+func (cb *CircuitBreaker) State(key string) State {
+	var transitions []transition
+	defer func() { cb.notify(transitions) }()
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	return cb.circuit(key, &transitions).state
+}
@@ -0,0 +1,147 @@
+package circuitbreaker
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	type step struct {
+		advance       time.Duration
+		record        *bool
+		cancel        bool
+		expectedAllow error
+		expectedState State
+	}
+	success, failure := true, false
+	testCases := []struct {
+		name                string
+		settings            Settings
+		steps               []step
+		expectedTransitions []string
+	}{
+		{
+			name:     "opens after consecutive failures",
+			settings: Settings{FailureThreshold: 2},
+			steps: []step{
+				{record: &failure, expectedState: Closed},
+				{record: &failure, expectedAllow: ErrOpen, expectedState: Open},
+			},
+			expectedTransitions: []string{"some-key: closed -> open"},
+		},
+		{
+			name:     "success resets consecutive failures",
+			settings: Settings{FailureThreshold: 2},
+			steps: []step{
+				{record: &failure, expectedState: Closed},
+				{record: &success, expectedState: Closed},
+				{record: &failure, expectedState: Closed},
+			},
+		},
+		{
+			name:     "half-opens after open timeout, closes on success",
+			settings: Settings{FailureThreshold: 1, OpenTimeout: time.Second},
+			steps: []step{
+				{record: &failure, expectedAllow: ErrOpen, expectedState: Open},
+				{advance: 500 * time.Millisecond, expectedAllow: ErrOpen, expectedState: Open},
+				{advance: 500 * time.Millisecond, expectedState: HalfOpen},
+				{record: &success, expectedState: Closed},
+			},
+			expectedTransitions: []string{
+				"some-key: closed -> open",
+				"some-key: open -> half-open",
+				"some-key: half-open -> closed",
+			},
+		},
+		{
+			name:     "half-open reopens on failure",
+			settings: Settings{FailureThreshold: 1, OpenTimeout: time.Second},
+			steps: []step{
+				{record: &failure, expectedAllow: ErrOpen, expectedState: Open},
+				{advance: time.Second, expectedState: HalfOpen},
+				{record: &failure, expectedAllow: ErrOpen, expectedState: Open},
+			},
+			expectedTransitions: []string{
+				"some-key: closed -> open",
+				"some-key: open -> half-open",
+				"some-key: half-open -> open",
+			},
+		},
+		{
+			name:     "canceled probe releases its slot",
+			settings: Settings{FailureThreshold: 1, OpenTimeout: time.Second},
+			steps: []step{
+				{record: &failure, expectedAllow: ErrOpen, expectedState: Open},
+				{advance: time.Second, cancel: true, expectedState: HalfOpen},
+				{expectedState: HalfOpen},
+			},
+			expectedTransitions: []string{
+				"some-key: closed -> open",
+				"some-key: open -> half-open",
+			},
+		},
+	}
+	originalNow := now
+	defer func() { now = originalNow }()
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			current := time.Date(2023, time.April, 9, 10, 0, 0, 0, time.UTC)
+			now = func() time.Time { return current }
+			var transitions []string
+			tc.settings.OnStateChange = func(key string, from, to State) {
+				transitions = append(transitions, fmt.Sprintf("%s: %s -> %s", key, from, to))
+			}
+			cb := New(tc.settings)
+			for i, s := range tc.steps {
+				current = current.Add(s.advance)
+				if s.record != nil {
+					require.NoError(t, cb.Allow("some-key"), "step %d", i)
+					cb.Record("some-key", *s.record)
+				}
+				if s.cancel {
+					require.NoError(t, cb.Allow("some-key"), "step %d", i)
+					cb.Cancel("some-key")
+				}
+				require.Equal(t, s.expectedState, cb.State("some-key"), "step %d", i)
+				require.Equal(t, s.expectedAllow, cb.Allow("some-key"), "step %d", i)
+				cb.Cancel("some-key")
+			}
+			require.Equal(t, tc.expectedTransitions, transitions)
+		})
+	}
+}
+
+func TestHalfOpenLimitsProbes(t *testing.T) {
+	originalNow := now
+	defer func() { now = originalNow }()
+	current := time.Now()
+	now = func() time.Time { return current }
+	cb := New(Settings{FailureThreshold: 1, OpenTimeout: time.Second, HalfOpenMaxRequests: 2})
+	cb.Record("some-key", false)
+	current = current.Add(time.Second)
+	require.NoError(t, cb.Allow("some-key"))
+	require.NoError(t, cb.Allow("some-key"))
+	require.Equal(t, ErrOpen, cb.Allow("some-key"))
+	cb.Record("some-key", true)
+	require.Equal(t, HalfOpen, cb.State("some-key"))
+	cb.Record("some-key", true)
+	require.Equal(t, Closed, cb.State("some-key"))
+}
+
+func TestCircuitsAreKeyed(t *testing.T) {
+	cb := New(Settings{FailureThreshold: 1})
+	cb.Record("some-key", false)
+	require.Equal(t, Open, cb.State("some-key"))
+	require.Equal(t, Closed, cb.State("another-key"))
+	require.NoError(t, cb.Allow("another-key"))
+}
+
+func TestStateString(t *testing.T) {
+	require.Equal(t, "closed", Closed.String())
+	require.Equal(t, "open", Open.String())
+	require.Equal(t, "half-open", HalfOpen.String())
+	require.Equal(t, "unknown", State(42).String())
+}
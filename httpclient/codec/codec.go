@@ -0,0 +1,73 @@
+// Package codec provides the codecs used to encode request bodies
+// and decode response bodies, registered by content type.
+// JSON, XML, form, MessagePack and protobuf codecs are
+// registered by default.
+package codec
+
+import (
+	"io"
+	"mime"
+	"strings"
+	"sync"
+)
+
+// Codec encodes and decodes bodies of a given content type.
+type Codec interface {
+	// ContentType returns the content type of the encoded bodies.
+	ContentType() string
+	// Encode writes the encoding of v to w.
+	Encode(w io.Writer, v any) error
+	// Decode reads the encoding of a value from r and stores it in v.
+	Decode(r io.Reader, v any) error
+}
+
+var (
+	mu     sync.RWMutex
+	codecs = make(map[string]Codec)
+)
+
+func init() {
+	Register(JSON, "application/json", "text/json")
+	Register(XML, "application/xml", "text/xml")
+	Register(Form, "application/x-www-form-urlencoded")
+	Register(MessagePack, "application/msgpack", "application/x-msgpack", "application/vnd.msgpack")
+	Register(Protobuf, "application/x-protobuf", "application/protobuf", "application/vnd.google.protobuf")
+}
+
+// Register registers the given codec for the given content types,
+// replacing any codec previously registered for them. When no
+// content type is given, the codec content type is used.
+func Register(c Codec, contentTypes ...string) {
+	if len(contentTypes) == 0 {
+		contentTypes = []string{c.ContentType()}
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	for _, contentType := range contentTypes {
+		codecs[strings.ToLower(contentType)] = c
+	}
+}
+
+// ForContentType returns the codec registered for the given content
+// type, whose parameters such as charset are ignored. Content types
+// with a +json or +xml structured syntax suffix, e.g.
+// application/problem+json, fall back to the JSON and XML codecs.
+func ForContentType(contentType string) (Codec, bool) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, false
+	}
+	mu.RLock()
+	c, ok := codecs[mediaType]
+	mu.RUnlock()
+	if ok {
+		return c, true
+	}
+	switch {
+	case strings.HasSuffix(mediaType, "+json"):
+		return ForContentType("application/json")
+	case strings.HasSuffix(mediaType, "+xml"):
+		return ForContentType("application/xml")
+	}
+	return nil, false
+}
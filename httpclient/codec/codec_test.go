@@ -0,0 +1,139 @@
+package codec
+
+import (
+	"bytes"
+	"io"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+type dummyType struct {
+	Key string `json:"key" xml:"key" msgpack:"key"`
+}
+
+// customCodec is a codec that ignores bodies.
+type customCodec struct{}
+
+func (customCodec) ContentType() string             { return "application/custom" }
+func (customCodec) Encode(w io.Writer, v any) error { return nil }
+func (customCodec) Decode(r io.Reader, v any) error { return nil }
+
+func TestCodecs(t *testing.T) {
+	testCases := []struct {
+		name     string
+		codec    Codec
+		value    any
+		decoded  func() any
+		expected string
+	}{
+		{
+			name:     "json",
+			codec:    JSON,
+			value:    dummyType{Key: "value"},
+			decoded:  func() any { return new(dummyType) },
+			expected: "{\"key\":\"value\"}\n",
+		},
+		{
+			name:     "xml",
+			codec:    XML,
+			value:    dummyType{Key: "value"},
+			decoded:  func() any { return new(dummyType) },
+			expected: "<dummyType><key>value</key></dummyType>",
+		},
+		{
+			name:     "form",
+			codec:    Form,
+			value:    url.Values{"key": {"value", "other value"}},
+			decoded:  func() any { return new(url.Values) },
+			expected: "key=value&key=other+value",
+		},
+		{
+			name:    "msgpack",
+			codec:   MessagePack,
+			value:   dummyType{Key: "value"},
+			decoded: func() any { return new(dummyType) },
+		},
+		{
+			name:    "protobuf",
+			codec:   Protobuf,
+			value:   wrapperspb.String("value"),
+			decoded: func() any { return new(wrapperspb.StringValue) },
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, tc.codec.Encode(&buf, tc.value))
+			if tc.expected != "" {
+				require.Equal(t, tc.expected, buf.String())
+			}
+			decoded := tc.decoded()
+			require.NoError(t, tc.codec.Decode(&buf, decoded))
+			if m, ok := decoded.(proto.Message); ok {
+				require.True(t, proto.Equal(tc.value.(proto.Message), m))
+				return
+			}
+			require.Equal(t, tc.value, reflect.ValueOf(decoded).Elem().Interface())
+		})
+	}
+}
+
+func TestFormCodec(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, Form.Encode(&buf, map[string]string{"key": "value"}))
+	require.Equal(t, "key=value", buf.String())
+	var m map[string]string
+	require.NoError(t, Form.Decode(&buf, &m))
+	require.Equal(t, map[string]string{"key": "value"}, m)
+	require.NotNil(t, Form.Encode(&buf, dummyType{}))
+	require.NotNil(t, Form.Decode(bytes.NewBufferString("key=value"), &dummyType{}))
+}
+
+func TestProtobufCodecRequiresMessages(t *testing.T) {
+	var buf bytes.Buffer
+	require.NotNil(t, Protobuf.Encode(&buf, dummyType{}))
+	require.NotNil(t, Protobuf.Decode(&buf, &dummyType{}))
+	var d *dummyType
+	require.NotNil(t, Protobuf.Decode(&buf, &d))
+	require.NotNil(t, Protobuf.Decode(&buf, (**wrapperspb.StringValue)(nil)))
+}
+
+func TestProtobufCodecDecodesIntoMessagePointers(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, Protobuf.Encode(&buf, wrapperspb.String("value")))
+	var m *wrapperspb.StringValue
+	require.NoError(t, Protobuf.Decode(&buf, &m))
+	require.Equal(t, "value", m.GetValue())
+}
+
+func TestForContentType(t *testing.T) {
+	Register(customCodec{})
+	testCases := []struct {
+		contentType   string
+		expectedCodec Codec
+	}{
+		{contentType: "application/json", expectedCodec: JSON},
+		{contentType: "Application/JSON; charset=utf-8", expectedCodec: JSON},
+		{contentType: "application/problem+json", expectedCodec: JSON},
+		{contentType: "text/xml; charset=utf-8", expectedCodec: XML},
+		{contentType: "application/atom+xml", expectedCodec: XML},
+		{contentType: "application/x-www-form-urlencoded", expectedCodec: Form},
+		{contentType: "application/x-msgpack", expectedCodec: MessagePack},
+		{contentType: "application/protobuf", expectedCodec: Protobuf},
+		{contentType: "application/custom", expectedCodec: customCodec{}},
+		{contentType: "text/plain"},
+		{contentType: ""},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.contentType, func(t *testing.T) {
+			c, ok := ForContentType(tc.contentType)
+			require.Equal(t, tc.expectedCodec != nil, ok)
+			require.Equal(t, tc.expectedCodec, c)
+		})
+	}
+}
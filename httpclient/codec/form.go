@@ -0,0 +1,68 @@
+package codec
+
+import (
+	"io"
+	"net/url"
+
+	"github.com/pkg/errors"
+)
+
+// Form encodes and decodes application/x-www-form-urlencoded bodies.
+// Values can be url.Values, map[string][]string or map[string]string,
+// and are decoded into pointers to any of them.
+var Form Codec = formCodec{}
+
+// formCodec is the form codec.
+type formCodec struct{}
+
+// ContentType returns application/x-www-form-urlencoded.
+func (formCodec) ContentType() string {
+	return "application/x-www-form-urlencoded"
+}
+
+// Encode writes the form encoding of v to w.
+func (formCodec) Encode(w io.Writer, v any) error {
+	var values url.Values
+	switch p := v.(type) {
+	case url.Values:
+		values = p
+	case map[string][]string:
+		values = p
+	case map[string]string:
+		values = make(url.Values, len(p))
+		for k, v := range p {
+			values.Set(k, v)
+		}
+	default:
+		return errors.Errorf("unsupported form value type %T", v)
+	}
+	_, err := io.WriteString(w, values.Encode())
+	return err
+}
+
+// Decode reads form encoded values from r and stores them in v.
+func (formCodec) Decode(r io.Reader, v any) error {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	values, err := url.ParseQuery(string(b))
+	if err != nil {
+		return err
+	}
+	switch p := v.(type) {
+	case *url.Values:
+		*p = values
+	case *map[string][]string:
+		*p = values
+	case *map[string]string:
+		m := make(map[string]string, len(values))
+		for k := range values {
+			m[k] = values.Get(k)
+		}
+		*p = m
+	default:
+		return errors.Errorf("unsupported form value type %T", v)
+	}
+	return nil
+}
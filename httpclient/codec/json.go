@@ -0,0 +1,27 @@
+package codec
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// JSON encodes and decodes bodies with encoding/json.
+var JSON Codec = jsonCodec{}
+
+// jsonCodec is the JSON codec.
+type jsonCodec struct{}
+
+// ContentType returns application/json.
+func (jsonCodec) ContentType() string {
+	return "application/json"
+}
+
+// Encode writes the JSON encoding of v to w.
+func (jsonCodec) Encode(w io.Writer, v any) error {
+	return json.NewEncoder(w).Encode(v)
+}
+
+// Decode reads a JSON encoded value from r and stores it in v.
+func (jsonCodec) Decode(r io.Reader, v any) error {
+	return json.NewDecoder(r).Decode(v)
+}
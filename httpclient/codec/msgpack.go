@@ -0,0 +1,28 @@
+package codec
+
+import (
+	"io"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// MessagePack encodes and decodes bodies with MessagePack.
+var MessagePack Codec = msgpackCodec{}
+
+// msgpackCodec is the MessagePack codec.
+type msgpackCodec struct{}
+
+// ContentType returns application/msgpack.
+func (msgpackCodec) ContentType() string {
+	return "application/msgpack"
+}
+
+// Encode writes the MessagePack encoding of v to w.
+func (msgpackCodec) Encode(w io.Writer, v any) error {
+	return msgpack.NewEncoder(w).Encode(v)
+}
+
+// Decode reads a MessagePack encoded value from r and stores it in v.
+func (msgpackCodec) Decode(r io.Reader, v any) error {
+	return msgpack.NewDecoder(r).Decode(v)
+}
@@ -0,0 +1,74 @@
+package codec
+
+import (
+	"io"
+	"reflect"
+
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/proto"
+)
+
+// Protobuf encodes and decodes bodies with protocol buffers.
+// Values must be proto.Message, or pointers to them when decoding.
+var Protobuf Codec = protobufCodec{}
+
+// messageType is the type of proto.Message.
+var messageType = reflect.TypeOf((*proto.Message)(nil)).Elem()
+
+// protobufCodec is the protobuf codec.
+type protobufCodec struct{}
+
+// ContentType returns application/x-protobuf.
+func (protobufCodec) ContentType() string {
+	return "application/x-protobuf"
+}
+
+// Encode writes the protobuf encoding of v to w.
+func (protobufCodec) Encode(w io.Writer, v any) error {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return errors.Errorf("%T is not a proto.Message", v)
+	}
+	b, err := proto.Marshal(m)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+// Decode reads a protobuf encoded message from r and stores it in v.
+// v may also be a pointer to a proto.Message pointer, e.g. when
+// decoding with httpclient.Get[*pb.Message], in which case the
+// message is allocated if nil.
+func (protobufCodec) Decode(r io.Reader, v any) error {
+	m, ok := message(v)
+	if !ok {
+		return errors.Errorf("%T is not a proto.Message", v)
+	}
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	return proto.Unmarshal(b, m)
+}
+
+// message returns the proto.Message v is, or the one v points
+// to, allocating it if nil.
+func message(v any) (proto.Message, bool) {
+	if m, ok := v.(proto.Message); ok {
+		return m, true
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return nil, false
+	}
+	elem := rv.Elem()
+	if elem.Kind() != reflect.Pointer || !elem.Type().Implements(messageType) {
+		return nil, false
+	}
+	if elem.IsNil() {
+		elem.Set(reflect.New(elem.Type().Elem()))
+	}
+	return elem.Interface().(proto.Message), true
+}
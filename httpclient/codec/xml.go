@@ -0,0 +1,27 @@
+package codec
+
+import (
+	"encoding/xml"
+	"io"
+)
+
+// XML encodes and decodes bodies with encoding/xml.
+var XML Codec = xmlCodec{}
+
+// xmlCodec is the XML codec.
+type xmlCodec struct{}
+
+// ContentType returns application/xml.
+func (xmlCodec) ContentType() string {
+	return "application/xml"
+}
+
+// Encode writes the XML encoding of v to w.
+func (xmlCodec) Encode(w io.Writer, v any) error {
+	return xml.NewEncoder(w).Encode(v)
+}
+
+// Decode reads a XML encoded value from r and stores it in v.
+func (xmlCodec) Decode(r io.Reader, v any) error {
+	return xml.NewDecoder(r).Decode(v)
+}
@@ -0,0 +1,71 @@
+package httpclient
+
+import (
+	"context"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tiagomelo/go-retryable-httpclient/httpclient/codec"
+)
+
+func TestResponseDecodedByContentType(t *testing.T) {
+	type payload struct {
+		XMLName xml.Name `xml:"payload"`
+		Key     string   `json:"key" xml:"key"`
+	}
+	testCases := []struct {
+		name        string
+		contentType string
+		body        string
+	}{
+		{
+			name:        "json",
+			contentType: "application/json; charset=utf-8",
+			body:        `{"key":"value"}`,
+		},
+		{
+			name:        "xml",
+			contentType: "application/xml",
+			body:        `<payload><key>value</key></payload>`,
+		},
+		{
+			name: "falls back to json",
+			body: `{"key":"value"}`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tc.contentType != "" {
+					w.Header().Set("Content-Type", tc.contentType)
+				}
+				w.Write([]byte(tc.body))
+			}))
+			defer svr.Close()
+			client := New()
+			req, err := NewRequest(context.TODO(), http.MethodGet, svr.URL)
+			require.NoError(t, err)
+			var v payload
+			_, err = client.SendRequestAndUnmarshallJsonResponse(req, &v)
+			require.NoError(t, err)
+			require.Equal(t, "value", v.Key)
+		})
+	}
+}
+
+func TestNewEncodedRequest(t *testing.T) {
+	req, err := NewEncodedRequest(context.TODO(), http.MethodPost, "http://localhost",
+		map[string]string{"key": "value"}, codec.Form)
+	require.NoError(t, err)
+	require.Equal(t, "application/x-www-form-urlencoded", req.Header.Get("Content-Type"))
+	body, err := io.ReadAll(req.Body)
+	require.NoError(t, err)
+	require.Equal(t, "key=value", string(body))
+	_, err = NewEncodedRequest(context.TODO(), http.MethodPost, "http://localhost",
+		dummyType{}, codec.Form)
+	require.NotNil(t, err)
+}
@@ -1,17 +1,37 @@
 package httpclient
 
 import (
+	"errors"
 	"fmt"
+	"net/http"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // HttpError is an error that wraps an HTTP response and/or an error.
+// Header holds the response headers. Attempts is the number of attempts
+// made, AttemptErrors holds the error of each of them, an *HttpError for
+// unsuccessful responses, and Elapsed is the time the whole call took.
+// Retryable tells whether the check retry policy deemed the last attempt
+// retryable, e.g. when giving up after running out of retries.
+// ErrorBody holds the response body decoded into the error type
+// registered through WithErrorBody or WithRequestErrorBody, if any.
+// Problem holds the problem details of application/problem+json
+// responses.
 type HttpError struct {
-	Url        string
-	StatusCode int
-	Body       string
-	Err        error
+	Url           string
+	Method        string
+	StatusCode    int
+	Header        http.Header
+	Body          string
+	ErrorBody     error
+	Problem       *ProblemDetails
+	Attempts      int
+	AttemptErrors []error
+	Elapsed       time.Duration
+	Retryable     bool
+	Err           error
 }
 
 // Error returns the error message. It implements the error interface.
@@ -20,6 +40,11 @@ func (e *HttpError) Error() string {
 	if e.StatusCode > 0 {
 		httpStatusCode = strconv.Itoa(e.StatusCode)
 	}
+	if e.Problem != nil {
+		return fmt.Sprintf("request to %v failed. "+
+			"httpStatus: [ %v ] problem: [ %v ] "+
+			"error: [ %v ]", e.Url, httpStatusCode, e.Problem, e.Err)
+	}
 	return fmt.Sprintf("request to %v failed. "+
 		"httpStatus: [ %v ] responseBody: [ %v ] "+
 		"error: [ %v ]", e.Url, httpStatusCode, e.Body, e.Err)
@@ -43,11 +68,16 @@ func sameErrors(err, anotherErr error) bool {
 }
 
 // Is returns true if the error is an HTTPError with the given
-// status code, body and error.
+// status code, body and error, or if its status code belongs
+// to the given StatusClass. When it returns false, errors.Is
+// goes on looking for the target in the wrapped error chain.
 func (e *HttpError) Is(targetErr error) bool {
 	if targetErr == nil {
 		return false
 	}
+	if class, ok := targetErr.(StatusClass); ok {
+		return class.matches(e.StatusCode)
+	}
 	t, ok := targetErr.(*HttpError)
 	if !ok {
 		return false
@@ -56,3 +86,19 @@ func (e *HttpError) Is(targetErr error) bool {
 		sameBodies(e.Body, t.Body) &&
 		sameErrors(e.Err, t.Err)
 }
+
+// As finds the first error in the decoded error body or in the
+// problem details that matches target, so that errors.As reaches
+// the registered error type and *ProblemDetails.
+func (e *HttpError) As(target any) bool {
+	if e.ErrorBody != nil && errors.As(e.ErrorBody, target) {
+		return true
+	}
+	return e.Problem != nil && errors.As(e.Problem, target)
+}
+
+// Unwrap returns the wrapped error, so that errors.Is and errors.As
+// reach transport errors such as context.DeadlineExceeded or *net.OpError.
+func (e *HttpError) Unwrap() error {
+	return e.Err
+}
@@ -1,9 +1,14 @@
 package httpclient
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"net"
 	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -97,6 +102,21 @@ func TestIs(t *testing.T) {
 				Err: http.ErrAbortHandler,
 			},
 		},
+		{
+			name: "target status class",
+			httpError: &HttpError{
+				StatusCode: http.StatusNotFound,
+			},
+			targetError:    ErrClientError,
+			expectedOutput: true,
+		},
+		{
+			name: "target different status class",
+			httpError: &HttpError{
+				StatusCode: http.StatusNotFound,
+			},
+			targetError: ErrServerError,
+		},
 	}
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -106,6 +126,86 @@ func TestIs(t *testing.T) {
 	}
 }
 
+func TestUnwrap(t *testing.T) {
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer svr.Close()
+	testCases := []struct {
+		name   string
+		url    string
+		ctx    func() (context.Context, context.CancelFunc)
+		expect func(t *testing.T, err error)
+	}{
+		{
+			name: "timeout",
+			url:  svr.URL,
+			ctx: func() (context.Context, context.CancelFunc) {
+				return context.WithTimeout(context.TODO(), 10*time.Millisecond)
+			},
+			expect: func(t *testing.T, err error) {
+				require.ErrorIs(t, err, context.DeadlineExceeded)
+			},
+		},
+		{
+			name: "cancellation",
+			url:  svr.URL,
+			ctx: func() (context.Context, context.CancelFunc) {
+				ctx, cancel := context.WithCancel(context.TODO())
+				time.AfterFunc(10*time.Millisecond, cancel)
+				return ctx, cancel
+			},
+			expect: func(t *testing.T, err error) {
+				require.ErrorIs(t, err, context.Canceled)
+			},
+		},
+		{
+			name: "dns failure",
+			url:  "http://nonexistent.invalid",
+			ctx: func() (context.Context, context.CancelFunc) {
+				return context.WithCancel(context.TODO())
+			},
+			expect: func(t *testing.T, err error) {
+				var dnsErr *net.DNSError
+				require.ErrorAs(t, err, &dnsErr)
+				var opErr *net.OpError
+				require.ErrorAs(t, err, &opErr)
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := tc.ctx()
+			defer cancel()
+			req, err := NewRequest(ctx, http.MethodGet, tc.url)
+			require.NoError(t, err)
+			_, err = New().SendRequest(req)
+			require.NotNil(t, err)
+			var httpErr *HttpError
+			require.ErrorAs(t, err, &httpErr)
+			require.Equal(t, httpErr.Err, errors.Unwrap(err))
+			tc.expect(t, err)
+		})
+	}
+}
+
+func TestIsKeepsFieldMatching(t *testing.T) {
+	cause := fmt.Errorf("giving up: %w", context.DeadlineExceeded)
+	err := fmt.Errorf("wrapped: %w", &HttpError{
+		StatusCode: http.StatusServiceUnavailable,
+		Err:        cause,
+	})
+	require.ErrorIs(t, err, &HttpError{StatusCode: http.StatusServiceUnavailable, Err: cause})
+	require.NotErrorIs(t, err, &HttpError{StatusCode: http.StatusBadGateway, Err: cause})
+	// Field matching compares the wrapped error as is,
+	// while errors.Is goes on down the wrapped chain.
+	require.NotErrorIs(t, err, &HttpError{Err: context.DeadlineExceeded})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
 func checkIfErrorIsExpected(t *testing.T, err, expectedError error) {
 	if expectedError == nil {
 		t.Fatalf(`expected no error, got "%v"`, err)
@@ -0,0 +1,22 @@
+package httpclient
+
+import (
+	"net/http"
+	"strings"
+)
+
+// decodeErrorBody decodes the body of the unsuccessful response
+// wrapped by the given error into the value returned by newErrorBody,
+// storing it in the error. Bodies that can't be decoded are left
+// as they are, in HttpError.Body.
+func decodeErrorBody(err error, resp *http.Response, newErrorBody func() error) {
+	httpErr, ok := err.(*HttpError)
+	if !ok || newErrorBody == nil || httpErr.Body == "" {
+		return
+	}
+	errorBody := newErrorBody()
+	if err := responseDecoder(resp)(strings.NewReader(httpErr.Body), errorBody); err != nil {
+		return
+	}
+	httpErr.ErrorBody = errorBody
+}
@@ -0,0 +1,130 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tiagomelo/go-retryable-httpclient/httpclient/retry/policies"
+)
+
+// apiError is the error body of a fictional API.
+type apiError struct {
+	Code    string   `json:"code"`
+	Message string   `json:"message"`
+	Details []string `json:"details"`
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+// otherApiError is the error body of another fictional API.
+type otherApiError struct {
+	Reason string `json:"reason"`
+}
+
+func (e *otherApiError) Error() string {
+	return e.Reason
+}
+
+func TestWithErrorBody(t *testing.T) {
+	testCases := []struct {
+		name              string
+		body              string
+		options           []Option
+		requestOptions    []RequestOption
+		expectedErrorBody error
+	}{
+		{
+			name:    "decodes error body",
+			body:    `{"code":"invalid","message":"invalid name","details":["too long"]}`,
+			options: []Option{WithErrorBody(func() error { return new(apiError) })},
+			expectedErrorBody: &apiError{
+				Code:    "invalid",
+				Message: "invalid name",
+				Details: []string{"too long"},
+			},
+		},
+		{
+			name:    "error body overridden per request",
+			body:    `{"reason":"invalid name"}`,
+			options: []Option{WithErrorBody(func() error { return new(apiError) })},
+			requestOptions: []RequestOption{
+				WithRequestErrorBody(func() error { return new(otherApiError) }),
+			},
+			expectedErrorBody: &otherApiError{Reason: "invalid name"},
+		},
+		{
+			name:    "body that can't be decoded",
+			body:    `invalid name`,
+			options: []Option{WithErrorBody(func() error { return new(apiError) })},
+		},
+		{
+			name: "without error body",
+			body: `{"code":"invalid","message":"invalid name"}`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				w.Write([]byte(tc.body))
+			}))
+			defer svr.Close()
+			client := New(tc.options...)
+			req, err := NewRequest(context.TODO(), http.MethodGet, svr.URL)
+			require.NoError(t, err)
+			_, err = client.SendRequest(req, tc.requestOptions...)
+			require.NotNil(t, err)
+			var httpErr *HttpError
+			require.True(t, errors.As(err, &httpErr))
+			require.Equal(t, tc.body, httpErr.Body)
+			require.Equal(t, tc.expectedErrorBody, httpErr.ErrorBody)
+			var apiErr *apiError
+			_, isApiError := tc.expectedErrorBody.(*apiError)
+			require.Equal(t, isApiError, errors.As(err, &apiErr))
+			if isApiError {
+				require.Equal(t, tc.expectedErrorBody, apiErr)
+			}
+		})
+	}
+}
+
+func TestWithErrorBodyWhenGivingUpRetrying(t *testing.T) {
+	for _, maxRetries := range []int{0, 2} {
+		t.Run(fmt.Sprintf("with %d retries", maxRetries), func(t *testing.T) {
+			var attempts int
+			svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				attempts++
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusServiceUnavailable)
+				w.Write([]byte(`{"code":"unavailable","message":"try again later"}`))
+			}))
+			defer svr.Close()
+			client := New(
+				WithMaxRetries(maxRetries),
+				WithRetryWaitMin(time.Millisecond),
+				WithRetryWaitMax(time.Millisecond),
+				WithCheckRetryPolicy(policies.StatusCodes(http.StatusServiceUnavailable)),
+				WithErrorBody(func() error { return new(apiError) }),
+			)
+			req, err := NewRequest(context.TODO(), http.MethodGet, svr.URL)
+			require.NoError(t, err)
+			_, err = client.SendRequest(req)
+			require.Equal(t, maxRetries+1, attempts)
+			var httpErr *HttpError
+			require.True(t, errors.As(err, &httpErr))
+			require.Equal(t, http.StatusServiceUnavailable, httpErr.StatusCode)
+			require.Equal(t, `{"code":"unavailable","message":"try again later"}`, httpErr.Body)
+			require.Equal(t, &apiError{Code: "unavailable", Message: "try again later"}, httpErr.ErrorBody)
+			require.Contains(t, httpErr.Err.Error(), fmt.Sprintf("giving up after %d attempt(s)", maxRetries+1))
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/pkg/errors"
+)
+
+// hedgedResult is the outcome of a single hedged call.
+type hedgedResult struct {
+	resp  *http.Response
+	log   *attemptLog
+	err   error
+	index int
+}
+
+// mayHedge reports whether the given request may be hedged
+// with the given options in effect.
+func mayHedge(req *http.Request, o *requestOptions) bool {
+	return o.hedgeDelay > 0 && o.maxHedges > 0 && isHedgeable(req.Method)
+}
+
+// isHedgeable reports whether requests with the given method may be
+// sent more than once concurrently. Unlike isIdempotent, it only
+// allows the methods that are idempotent by definition.
+func isHedgeable(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions,
+		http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// hedge sends the given request and, every time the hedge delay
+// elapses without a response, sends a duplicate of it, up to the
+// maximum number of hedges. Each of them goes through the whole
+// retry loop. The first response to arrive is returned, and the
+// other calls are canceled, their responses being closed.
+// When every call fails, the outcome of the last one is returned.
+// The attempt log of the returned call is returned along. Each hedge
+// is let through the circuit breaker on its own, so that a half-open
+// circuit doesn't get more probes than it allows.
+func (c *Client) hedge(req *retryablehttp.Request, o *requestOptions) (*http.Response, *attemptLog, error) {
+	// Calls run concurrently, so they can't share a body reader
+	// that is rewound before every attempt.
+	body, err := req.BodyBytes()
+	if err != nil {
+		return nil, new(attemptLog), errors.Wrap(err, "reading request body")
+	}
+	if body != nil {
+		if err := req.SetBody(body); err != nil {
+			return nil, new(attemptLog), errors.Wrap(err, "reading request body")
+		}
+	}
+	results := make(chan hedgedResult, o.maxHedges+1)
+	cancels := make([]context.CancelFunc, 0, o.maxHedges+1)
+	send := func() {
+		ctx, cancel := context.WithCancel(req.Context())
+		hedgedReq := req.WithContext(ctx)
+		index := len(cancels)
+		cancels = append(cancels, cancel)
+		go func() {
+			log := new(attemptLog)
+			resp, err := retryableHttpClientDo(c.retryableClient(hedgedReq.Request, o, log), hedgedReq)
+			results <- hedgedResult{resp: resp, log: log, err: err, index: index}
+		}()
+	}
+	send()
+	timer := time.NewTimer(o.hedgeDelay)
+	defer timer.Stop()
+	pending := 1
+	for {
+		select {
+		case <-timer.C:
+			if len(cancels) > o.maxHedges {
+				continue
+			}
+			// Hedges add load just like retries do.
+			if c.retryBudget != nil && !c.retryBudget.AllowRetry() {
+				continue
+			}
+			if c.breaker != nil && c.breaker.Allow(c.circuitBreakerKey(req.Request)) != nil {
+				continue
+			}
+			send()
+			pending++
+			timer.Reset(o.hedgeDelay)
+		case result := <-results:
+			pending--
+			if (result.resp == nil || result.err != nil) && pending > 0 {
+				if result.resp != nil && result.resp.Body != nil {
+					result.resp.Body.Close()
+				}
+				c.releaseUnrecordedProbe(req.Request, result.log)
+				cancels[result.index]()
+				continue
+			}
+			for i, cancel := range cancels {
+				if i != result.index {
+					cancel()
+				}
+			}
+			go c.closeHedgedResponses(req.Request, results, pending)
+			return keepUntilClosed(result.resp, cancels[result.index]), result.log, result.err
+		}
+	}
+}
+
+// keepUntilClosed returns the given response, canceling its
+// context once the response body is closed.
+func keepUntilClosed(resp *http.Response, cancel context.CancelFunc) *http.Response {
+	if resp == nil || resp.Body == nil {
+		cancel()
+		return resp
+	}
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	return resp
+}
+
+// closeHedgedResponses waits for the given number of canceled calls
+// of the given request and closes their responses.
+func (c *Client) closeHedgedResponses(req *http.Request, results <-chan hedgedResult, pending int) {
+	for i := 0; i < pending; i++ {
+		result := <-results
+		if result.resp != nil && result.resp.Body != nil {
+			result.resp.Body.Close()
+		}
+		c.releaseUnrecordedProbe(req, result.log)
+	}
+}
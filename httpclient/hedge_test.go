@@ -0,0 +1,191 @@
+package httpclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tiagomelo/go-retryable-httpclient/httpclient/circuitbreaker"
+)
+
+func TestWithHedging(t *testing.T) {
+	testCases := []struct {
+		name             string
+		method           string
+		requestOptions   []RequestOption
+		expectedAttempts int
+		expectedBody     string
+	}{
+		{
+			name:             "first response wins",
+			method:           http.MethodGet,
+			expectedAttempts: 3,
+			expectedBody:     "attempt 3",
+		},
+		{
+			name:             "body is sent on every hedge",
+			method:           http.MethodPut,
+			expectedAttempts: 3,
+			expectedBody:     "attempt 3",
+		},
+		{
+			name:             "non-idempotent requests are not hedged",
+			method:           http.MethodPost,
+			expectedAttempts: 1,
+			expectedBody:     "attempt 1",
+		},
+		{
+			name:             "custom methods are not hedged",
+			method:           "PURGE",
+			expectedAttempts: 1,
+			expectedBody:     "attempt 1",
+		},
+		{
+			name:             "hedging overridden per request",
+			method:           http.MethodGet,
+			requestOptions:   []RequestOption{WithRequestHedging(0, 0)},
+			expectedAttempts: 1,
+			expectedBody:     "attempt 1",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				mu       sync.Mutex
+				attempts int
+				canceled int
+			)
+			svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, err := io.ReadAll(r.Body)
+				require.NoError(t, err)
+				require.Equal(t, "payload", string(body))
+				mu.Lock()
+				attempts++
+				attempt := attempts
+				mu.Unlock()
+				// Only the third attempt responds right away.
+				if attempt != 3 {
+					select {
+					case <-r.Context().Done():
+						mu.Lock()
+						canceled++
+						mu.Unlock()
+						return
+					case <-time.After(200 * time.Millisecond):
+					}
+				}
+				w.Write([]byte("attempt " + strconv.Itoa(attempt)))
+			}))
+			defer svr.Close()
+			client := New(WithHedging(20*time.Millisecond, 3))
+			req, err := NewRequestWithBody(context.TODO(), tc.method, svr.URL, "payload")
+			require.NoError(t, err)
+			resp, err := client.SendRequest(req, tc.requestOptions...)
+			require.NoError(t, err)
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			require.NoError(t, resp.Body.Close())
+			require.Equal(t, tc.expectedBody, string(body))
+			svr.Close()
+			require.Equal(t, tc.expectedAttempts, attempts)
+			require.Equal(t, tc.expectedAttempts-1, canceled)
+		})
+	}
+}
+
+func TestWithHedgingLetsEveryHedgeThroughTheCircuitBreaker(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		attempts int
+	)
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		attempts++
+		mu.Unlock()
+		time.Sleep(50 * time.Millisecond)
+	}))
+	defer svr.Close()
+	key := svr.Listener.Addr().String()
+	breaker := circuitbreaker.New(circuitbreaker.Settings{
+		FailureThreshold:    1,
+		OpenTimeout:         time.Millisecond,
+		HalfOpenMaxRequests: 1,
+	})
+	breaker.Record(key, false)
+	time.Sleep(time.Millisecond)
+	require.Equal(t, circuitbreaker.HalfOpen, breaker.State(key))
+	client := New(
+		WithHedging(5*time.Millisecond, 3),
+		WithCircuitBreaker(breaker),
+	)
+	req, err := NewRequest(context.TODO(), http.MethodGet, svr.URL)
+	require.NoError(t, err)
+	resp, err := client.SendRequest(req)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	svr.Close()
+	// Only the probe reaches the upstream.
+	require.Equal(t, 1, attempts)
+	require.Equal(t, circuitbreaker.Closed, breaker.State(key))
+}
+
+func TestWithHedgingReturnsLastErrorWhenAllFail(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		attempts int
+	)
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		attempts++
+		mu.Unlock()
+		time.Sleep(50 * time.Millisecond)
+		hj, ok := w.(http.Hijacker)
+		require.True(t, ok)
+		conn, _, err := hj.Hijack()
+		require.NoError(t, err)
+		conn.Close()
+	}))
+	defer svr.Close()
+	client := New(WithHedging(10*time.Millisecond, 2))
+	req, err := NewRequest(context.TODO(), http.MethodGet, svr.URL)
+	require.NoError(t, err)
+	resp, err := client.SendRequest(req)
+	require.Nil(t, resp)
+	require.NotNil(t, err)
+	require.Equal(t, 3, attempts)
+}
+
+func TestWithHedgingDoesNotHedgeBodiesThatCannotBeRewound(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		attempts int
+		bodies   []string
+	)
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		mu.Lock()
+		attempts++
+		bodies = append(bodies, string(body))
+		mu.Unlock()
+		time.Sleep(50 * time.Millisecond)
+	}))
+	defer svr.Close()
+	client := New(WithHedging(10*time.Millisecond, 2))
+	req, err := NewRequest(context.TODO(), http.MethodPut, svr.URL)
+	require.NoError(t, err)
+	// A streaming body, which can only be read once.
+	req.Body = io.NopCloser(strings.NewReader("0123456789abcdefghijklmnopqr"))
+	resp, err := client.SendRequest(req)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, 1, attempts)
+	require.Equal(t, []string{"0123456789abcdefghijklmnopqr"}, bodies)
+}
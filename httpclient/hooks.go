@@ -0,0 +1,127 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+)
+
+// AttemptEvent describes an attempt of a call to the Hooks.
+type AttemptEvent struct {
+	// Request is the request of the attempt.
+	Request *http.Request
+	// Attempt is the attempt number, starting at 1.
+	// For OnGiveUp, it is the number of attempts made.
+	Attempt int
+	// Response is the response of the attempt, if any.
+	// Its body must not be read. It is nil for OnGiveUp.
+	Response *http.Response
+	// Err is the error of the attempt: the error it got or an
+	// *HttpError for an unsuccessful response. For OnGiveUp,
+	// it is the error the call returns.
+	Err error
+	// Duration is how long the attempt took.
+	// For OnGiveUp, it is how long the whole call took.
+	Duration time.Duration
+	// Wait is how long is waited before the next attempt.
+	// It is only set for OnRetry.
+	Wait time.Duration
+}
+
+// Hooks are functions called along the attempts of every call.
+// Any of them may be nil. They are called synchronously, so they
+// should return quickly; hedged calls may call them concurrently.
+type Hooks struct {
+	// OnAttemptStart is called before every attempt is made.
+	OnAttemptStart func(event AttemptEvent)
+	// OnAttemptEnd is called after every attempt, once
+	// the check retry policy has been applied.
+	OnAttemptEnd func(event AttemptEvent)
+	// OnRetry is called when an attempt is going to be retried,
+	// before waiting for the next one.
+	OnRetry func(event AttemptEvent)
+	// OnGiveUp is called once when a call fails for good.
+	OnGiveUp func(event AttemptEvent)
+}
+
+// empty reports whether none of the hooks is set.
+func (h Hooks) empty() bool {
+	return h.OnAttemptStart == nil && h.OnAttemptEnd == nil &&
+		h.OnRetry == nil && h.OnGiveUp == nil
+}
+
+// attemptHooks calls the hooks along the attempts of a single call.
+type attemptHooks struct {
+	hooks      Hooks
+	req        *http.Request
+	attemptNum int
+	start      time.Time
+	last       AttemptEvent
+}
+
+// requestLogHook wraps the given request log hook, calling
+// OnAttemptStart before every attempt is made.
+func (h *attemptHooks) requestLogHook(hook retryablehttp.RequestLogHook) retryablehttp.RequestLogHook {
+	return func(logger retryablehttp.Logger, req *http.Request, attemptNum int) {
+		h.attemptNum = attemptNum
+		h.start = now()
+		if hook != nil {
+			hook(logger, req, attemptNum)
+		}
+		if h.hooks.OnAttemptStart != nil {
+			h.hooks.OnAttemptStart(AttemptEvent{Request: req, Attempt: attemptNum + 1})
+		}
+	}
+}
+
+// checkRetry wraps the given check retry policy,
+// calling OnAttemptEnd after every attempt.
+func (h *attemptHooks) checkRetry(checkRetry retryablehttp.CheckRetry) retryablehttp.CheckRetry {
+	return func(ctx context.Context, resp *http.Response, err error) (bool, error) {
+		retry, checkErr := checkRetry(ctx, resp, err)
+		attemptErr := attemptError(h.req, resp, err)
+		if attemptErr == nil {
+			attemptErr = checkErr
+		}
+		h.last = AttemptEvent{
+			Request:  h.req,
+			Attempt:  h.attemptNum + 1,
+			Response: resp,
+			Err:      attemptErr,
+			Duration: now().Sub(h.start),
+		}
+		if h.hooks.OnAttemptEnd != nil {
+			h.hooks.OnAttemptEnd(h.last)
+		}
+		return retry, checkErr
+	}
+}
+
+// backoff wraps the given backoff, calling OnRetry
+// with the wait before the next attempt.
+func (h *attemptHooks) backoff(backoff retryablehttp.Backoff) retryablehttp.Backoff {
+	return func(min, max time.Duration, attemptNum int, resp *http.Response) time.Duration {
+		wait := backoff(min, max, attemptNum, resp)
+		if h.hooks.OnRetry != nil {
+			event := h.last
+			event.Wait = wait
+			h.hooks.OnRetry(event)
+		}
+		return wait
+	}
+}
+
+// giveUp calls OnGiveUp for a call of the given request
+// that failed with the given error.
+func (c *Client) giveUp(req *http.Request, log *attemptLog, err error, start time.Time) {
+	if c.hooks.OnGiveUp != nil {
+		c.hooks.OnGiveUp(AttemptEvent{
+			Request:  req,
+			Attempt:  len(log.errs),
+			Err:      err,
+			Duration: now().Sub(start),
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tiagomelo/go-retryable-httpclient/httpclient/retry/policies"
+)
+
+// statusServer returns a server responding with the given
+// statuses in turn, the last one being repeated.
+func statusServer(statuses ...int) *httptest.Server {
+	var mu sync.Mutex
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		w.WriteHeader(statuses[0])
+		if len(statuses) > 1 {
+			statuses = statuses[1:]
+		}
+	}))
+}
+
+func TestWithHooks(t *testing.T) {
+	testCases := []struct {
+		name             string
+		statuses         []int
+		expectedEnds     []int
+		expectedRetries  []int
+		expectedGiveUps  []int
+		expectedErrorOut bool
+	}{
+		{
+			name:            "retried until success",
+			statuses:        []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusOK},
+			expectedEnds:    []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusOK},
+			expectedRetries: []int{1, 2},
+		},
+		{
+			name:             "retries exhausted",
+			statuses:         []int{http.StatusServiceUnavailable},
+			expectedEnds:     []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusServiceUnavailable},
+			expectedRetries:  []int{1, 2},
+			expectedGiveUps:  []int{3},
+			expectedErrorOut: true,
+		},
+		{
+			name:             "not retried",
+			statuses:         []int{http.StatusNotFound},
+			expectedEnds:     []int{http.StatusNotFound},
+			expectedGiveUps:  []int{1},
+			expectedErrorOut: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			svr := statusServer(tc.statuses...)
+			defer svr.Close()
+			var (
+				starts   []int
+				ends     []int
+				retries  []int
+				giveUps  []int
+				giveUpEv AttemptEvent
+			)
+			client := New(
+				WithMaxRetries(2),
+				WithCheckRetryPolicy(policies.StatusCodes(http.StatusServiceUnavailable)),
+				WithBackoff(func(min, max time.Duration, attemptNum int, resp *http.Response) time.Duration {
+					return time.Millisecond
+				}),
+				WithHooks(Hooks{
+					OnAttemptStart: func(event AttemptEvent) {
+						starts = append(starts, event.Attempt)
+					},
+					OnAttemptEnd: func(event AttemptEvent) {
+						require.Equal(t, len(ends)+1, event.Attempt)
+						require.Equal(t, event.Response.StatusCode >= http.StatusBadRequest, event.Err != nil)
+						ends = append(ends, event.Response.StatusCode)
+					},
+					OnRetry: func(event AttemptEvent) {
+						require.Equal(t, time.Millisecond, event.Wait)
+						require.True(t, IsStatus(event.Err, http.StatusServiceUnavailable))
+						retries = append(retries, event.Attempt)
+					},
+					OnGiveUp: func(event AttemptEvent) {
+						giveUps = append(giveUps, event.Attempt)
+						giveUpEv = event
+					},
+				}),
+			)
+			req, err := NewRequest(context.TODO(), http.MethodGet, svr.URL)
+			require.NoError(t, err)
+			_, err = client.SendRequest(req)
+			require.Equal(t, tc.expectedErrorOut, err != nil)
+			require.Len(t, starts, len(tc.expectedEnds))
+			require.Equal(t, tc.expectedEnds, ends)
+			require.Equal(t, tc.expectedRetries, retries)
+			require.Equal(t, tc.expectedGiveUps, giveUps)
+			if tc.expectedErrorOut {
+				require.Equal(t, err, giveUpEv.Err)
+				require.Equal(t, req.URL.String(), giveUpEv.Request.URL.String())
+			}
+		})
+	}
+}
+
+func TestDumpsForEveryAttempt(t *testing.T) {
+	svr := statusServer(http.StatusServiceUnavailable, http.StatusOK)
+	defer svr.Close()
+	var requestDumps, responseDumps []string
+	client := New(
+		WithMaxRetries(1),
+		WithRetryWaitMin(time.Millisecond),
+		WithRetryWaitMax(time.Millisecond),
+		WithCheckRetryPolicy(policies.StatusCodes(http.StatusServiceUnavailable)),
+		WithRequestDumpLogger(func(dump []byte) { requestDumps = append(requestDumps, string(dump)) }, true),
+		WithResponseDumpLogger(func(dump []byte) { responseDumps = append(responseDumps, string(dump)) }, false),
+	)
+	req, err := NewRequestWithBody(context.TODO(), http.MethodPut, svr.URL, "payload")
+	require.NoError(t, err)
+	_, err = client.SendRequest(req)
+	require.NoError(t, err)
+	require.Len(t, requestDumps, 2)
+	for _, dump := range requestDumps {
+		require.True(t, strings.HasPrefix(dump, "PUT / HTTP/1.1\r\n"))
+		require.True(t, strings.HasSuffix(dump, "\r\n\r\npayload"))
+	}
+	require.Len(t, responseDumps, 2)
+	require.True(t, strings.HasPrefix(responseDumps[0], "HTTP/1.1 503 Service Unavailable\r\n"))
+	require.True(t, strings.HasPrefix(responseDumps[1], "HTTP/1.1 200 OK\r\n"))
+}
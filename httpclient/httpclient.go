@@ -4,12 +4,19 @@ package httpclient
 import (
 	"encoding/json"
 	"io"
+	"log/slog"
 	"net/http"
 	"net/http/httputil"
 	"time"
 
 	"github.com/hashicorp/go-retryablehttp"
 	"github.com/pkg/errors"
+	"github.com/tiagomelo/go-retryable-httpclient/httpclient/adaptive"
+	"github.com/tiagomelo/go-retryable-httpclient/httpclient/bulkhead"
+	"github.com/tiagomelo/go-retryable-httpclient/httpclient/circuitbreaker"
+	"github.com/tiagomelo/go-retryable-httpclient/httpclient/codec"
+	"github.com/tiagomelo/go-retryable-httpclient/httpclient/ratelimit"
+	"github.com/tiagomelo/go-retryable-httpclient/httpclient/retry/budget"
 	"github.com/tiagomelo/go-retryable-httpclient/httpclient/retry/policies"
 )
 
@@ -48,11 +55,12 @@ var (
 		}
 		return nil
 	}
-	decodeResponse = func(url string, resp *http.Response, v any) error {
+	decodeResponse = func(url string, resp *http.Response, v any,
+		decode func(r io.Reader, v any) error) error {
 		if v != nil {
 			if resp != nil {
 				defer resp.Body.Close()
-				if err := jsonDecode(resp.Body, v); err != nil {
+				if err := decode(resp.Body, v); err != nil {
 					return &HttpError{
 						Url:        url,
 						StatusCode: resp.StatusCode,
@@ -66,6 +74,14 @@ var (
 	jsonDecode = func(r io.Reader, data any) error {
 		return json.NewDecoder(r).Decode(data)
 	}
+	responseDecoder = func(resp *http.Response) func(r io.Reader, v any) error {
+		if resp != nil {
+			if c, ok := codec.ForContentType(resp.Header.Get("Content-Type")); ok {
+				return c.Decode
+			}
+		}
+		return jsonDecode
+	}
 	ioReadAll = func(r io.Reader) ([]byte, error) {
 		return io.ReadAll(r)
 	}
@@ -79,20 +95,41 @@ var (
 
 // Client represents an http client.
 type Client struct {
-	httpClient          *http.Client
-	retryableHttpClient *retryablehttp.Client
-	timeout             time.Duration
-	maxIdleConns        int
-	maxIdleConnsPerHost int
-	maxConnsPerHost     int
-	maxRetries          int
-	checkRetryPolicy    retryablehttp.CheckRetry
-	retryWaitMin        time.Duration
-	retryWaitMax        time.Duration
-	requestDumpLogger   func(dump []byte)
-	dumpRequestBody     bool
-	responseDumpLogger  func(dump []byte)
-	dumpResponseBody    bool
+	httpClient              *http.Client
+	retryableHttpClient     *retryablehttp.Client
+	timeout                 time.Duration
+	attemptTimeout          time.Duration
+	totalTimeout            time.Duration
+	maxIdleConns            int
+	maxIdleConnsPerHost     int
+	maxConnsPerHost         int
+	maxRetries              int
+	checkRetryPolicy        retryablehttp.CheckRetry
+	retryWaitMin            time.Duration
+	retryWaitMax            time.Duration
+	backoff                 retryablehttp.Backoff
+	honorRetryAfter         bool
+	retryAfterMaxWait       time.Duration
+	idempotentRetries       bool
+	generateIdempotencyKeys bool
+	requestDumpLogger       func(dump []byte)
+	dumpRequestBody         bool
+	responseDumpLogger      func(dump []byte)
+	dumpResponseBody        bool
+	breaker                 *circuitbreaker.CircuitBreaker
+	breakerKey              func(req *http.Request) string
+	limiter                 *ratelimit.Limiter
+	hostLimiter             *ratelimit.KeyedLimiter
+	retryBudget             *budget.Budget
+	bulkhead                *bulkhead.Bulkhead
+	concurrencyLimiter      *adaptive.Limiter
+	hedgeDelay              time.Duration
+	maxHedges               int
+	newErrorBody            func() error
+	logger                  *slog.Logger
+	redactor                *redactor
+	hooks                   Hooks
+	tracer                  Tracer
 }
 
 // patchRetryableClient patches retryable http client.
@@ -103,10 +140,107 @@ func patchRetryableClient(client *Client) {
 	client.retryableHttpClient.HTTPClient = client.httpClient
 	// If no custom check retry policy is provided,
 	// DoNotRetry policy will be used.
-	client.retryableHttpClient.CheckRetry = policies.DoNotRetry
-	if client.checkRetryPolicy != nil {
-		client.retryableHttpClient.CheckRetry = client.checkRetryPolicy
+	if client.checkRetryPolicy == nil {
+		client.checkRetryPolicy = policies.DoNotRetry
+	}
+	client.retryableHttpClient.CheckRetry = client.wrapCheckRetry(client.checkRetryPolicy)
+	if client.backoff != nil {
+		client.retryableHttpClient.Backoff = client.backoff
+	}
+	if logger, ok := client.retryableHttpClient.Logger.(retryablehttp.Logger); ok {
+		client.retryableHttpClient.Logger = &redactingPrinter{logger: logger, redactor: client.redactor}
+	}
+	if client.logger != nil {
+		client.retryableHttpClient.Logger = &redactingLogger{logger: client.logger, redactor: client.redactor}
+	}
+	if client.honorRetryAfter {
+		client.retryableHttpClient.Backoff = retryAfterBackoff(client.retryableHttpClient.Backoff,
+			client.retryAfterWaitLimit())
+	}
+}
+
+// retryAfterWaitLimit returns the longest wait asked
+// by the server that is honored.
+func (c *Client) retryAfterWaitLimit() time.Duration {
+	switch {
+	case c.retryAfterMaxWait > 0:
+		return c.retryAfterMaxWait
+	case c.retryWaitMax > 0:
+		return c.retryWaitMax
+	}
+	return defaultRetryAfterMaxWait
+}
+
+// wrapCheckRetry wraps the given check retry policy
+// with the retry behaviors enabled for the client.
+func (c *Client) wrapCheckRetry(checkRetry retryablehttp.CheckRetry) retryablehttp.CheckRetry {
+	if c.honorRetryAfter {
+		checkRetry = retryAfterCheckRetry(checkRetry, c.retryAfterWaitLimit())
 	}
+	return checkRetry
+}
+
+// retryableClient returns a retryable http client for
+// a single call of the given request, with the given options in effect.
+// The outcome of every attempt is recorded in the given attempt log.
+func (c *Client) retryableClient(req *http.Request, o *requestOptions, log *attemptLog) *retryablehttp.Client {
+	rc := c.retryableHttpClient
+	httpClient := rc.HTTPClient
+	if o.attemptTimeout != httpClient.Timeout {
+		hc := *httpClient
+		hc.Timeout = o.attemptTimeout
+		httpClient = &hc
+	}
+	// Rate limiters are waited on first, so that no turn
+	// in the bulkhead is held while waiting for them.
+	// Attempts are traced along with those waits.
+	httpClient = c.tracedClient(c.rateLimitedClient(c.bulkheadClient(c.adaptiveClient(httpClient))))
+	checkRetry := log.checkRetry(req, o.checkRetryPolicy)
+	if c.breaker != nil {
+		checkRetry = breakerCheckRetry(c.breaker, c.circuitBreakerKey(req), checkRetry)
+	}
+	attempts := &attemptBudget{
+		attemptTimeout: o.attemptTimeout,
+		maxRetries:     o.maxRetries,
+		nextBackoff:    rc.Backoff,
+		retryBudget:    c.retryBudget,
+	}
+	client := &retryablehttp.Client{
+		HTTPClient:      httpClient,
+		Logger:          rc.Logger,
+		RetryWaitMin:    rc.RetryWaitMin,
+		RetryWaitMax:    rc.RetryWaitMax,
+		RetryMax:        o.maxRetries,
+		RequestLogHook:  rc.RequestLogHook,
+		ResponseLogHook: rc.ResponseLogHook,
+		CheckRetry:      attempts.checkRetry(c.wrapCheckRetry(checkRetry), rc.RetryWaitMin, rc.RetryWaitMax),
+		Backoff:         attempts.backoff,
+		ErrorHandler:    keepLastResponse(req),
+	}
+	if c.logger != nil {
+		logger := &attemptLogger{
+			logger:     c.logger,
+			redactor:   c.redactor,
+			req:        req,
+			maxRetries: o.maxRetries,
+		}
+		client.RequestLogHook = logger.requestLogHook(client.RequestLogHook)
+		client.CheckRetry = logger.checkRetry(client.CheckRetry)
+	}
+	if !c.hooks.empty() {
+		hooks := &attemptHooks{
+			hooks: c.hooks,
+			req:   req,
+		}
+		client.RequestLogHook = hooks.requestLogHook(client.RequestLogHook)
+		client.CheckRetry = hooks.checkRetry(client.CheckRetry)
+		client.Backoff = hooks.backoff(client.Backoff)
+	}
+	if o.dumpLogging {
+		client.RequestLogHook = c.requestDumpHook(client.RequestLogHook)
+		client.ResponseLogHook = c.responseDumpHook(client.ResponseLogHook)
+	}
+	return client
 }
 
 // patchTransport patches the specified client with
@@ -131,7 +265,7 @@ func patchTransport(client *Client) {
 
 // newClient returns a new Client with options loaded.
 func newClient(options []Option) *Client {
-	client := new(Client)
+	client := &Client{redactor: newRedactor()}
 	for _, option := range options {
 		option(client)
 	}
@@ -153,33 +287,62 @@ func New(options ...Option) *Client {
 }
 
 // do performs a request and parses the response to the given interface, if provided.
-func (c *Client) do(req *retryablehttp.Request, v any) (*http.Response, error) {
-	resp, err := retryableHttpClientDo(c.retryableHttpClient, req)
-	c.logResponseDump(resp)
+func (c *Client) do(req *retryablehttp.Request, v any, o *requestOptions) (*http.Response, error) {
+	start := now()
+	req, release := withTotalTimeout(req, o.totalTimeout)
+	var (
+		resp *http.Response
+		log  = new(attemptLog)
+		err  error
+	)
+	if mayHedge(req.Request, o) {
+		resp, log, err = c.hedge(req, o)
+	} else {
+		resp, err = retryableHttpClientDo(c.retryableClient(req.Request, o, log), req)
+	}
+	release(resp)
+	c.releaseUnrecordedProbe(req.Request, log)
 	if err := handleUnsuccessfulResponse(req.URL.String(), resp, err); err != nil {
+		decodeErrorBody(err, resp, o.newErrorBody)
+		decodeProblemDetails(err, resp)
+		log.annotate(err, req.Request, resp, start)
+		c.giveUp(req.Request, log, err, start)
 		return resp, err
 	}
-	if err := decodeResponse(req.URL.String(), resp, v); err != nil {
+	decode := o.decode
+	if decode == nil {
+		decode = responseDecoder(resp)
+	}
+	if err := decodeResponse(req.URL.String(), resp, v, decode); err != nil {
+		log.annotate(err, req.Request, resp, start)
 		return resp, err
 	}
 	return resp, nil
 }
 
-// logRequestDump logs the request dump.
+// logRequestDump logs the request dump, with the redacted values masked.
 func (c *Client) logRequestDump(req *http.Request) {
 	if c.requestDumpLogger != nil {
-		dump, err := dumpRequestOut(req, c.dumpRequestBody)
+		redacted, err := c.redactor.request(req, c.dumpRequestBody)
+		if err != nil {
+			return
+		}
+		dump, err := dumpRequestOut(redacted, c.dumpRequestBody)
 		if err == nil {
 			c.requestDumpLogger(dump)
 		}
 	}
 }
 
-// logResponseDump logs the response dump.
+// logResponseDump logs the response dump, with the redacted values masked.
 func (c *Client) logResponseDump(resp *http.Response) {
 	if resp != nil {
 		if c.responseDumpLogger != nil {
-			dump, err := dumpResponse(resp, c.dumpResponseBody)
+			redacted, err := c.redactor.response(resp, c.dumpResponseBody)
+			if err != nil {
+				return
+			}
+			dump, err := dumpResponse(redacted, c.dumpResponseBody)
 			if err == nil {
 				c.responseDumpLogger(dump)
 			}
@@ -187,10 +350,82 @@ func (c *Client) logResponseDump(resp *http.Response) {
 	}
 }
 
-// sendRequest sends a request with or without payload.
-func (c *Client) sendRequest(req *http.Request, v any) (*http.Response, error) {
-	c.logRequestDump(req)
-	resp, err := c.do(&retryablehttp.Request{Request: req}, v)
+// requestDumpHook wraps the given request log hook,
+// logging the request dump of every attempt.
+func (c *Client) requestDumpHook(hook retryablehttp.RequestLogHook) retryablehttp.RequestLogHook {
+	return func(logger retryablehttp.Logger, req *http.Request, attemptNum int) {
+		if hook != nil {
+			hook(logger, req, attemptNum)
+		}
+		c.logRequestDump(req)
+	}
+}
+
+// responseDumpHook wraps the given response log hook,
+// logging the response dump of every attempt.
+func (c *Client) responseDumpHook(hook retryablehttp.ResponseLogHook) retryablehttp.ResponseLogHook {
+	return func(logger retryablehttp.Logger, resp *http.Response) {
+		if hook != nil {
+			hook(logger, resp)
+		}
+		c.logResponseDump(resp)
+	}
+}
+
+// sendRequest sends a request with or without payload,
+// tracing the call when a tracer is set.
+func (c *Client) sendRequest(req *http.Request, v any, options []RequestOption) (*http.Response, error) {
+	if c.tracer == nil {
+		return c.send(req, v, options)
+	}
+	ctx, end := c.tracer.StartCall(req.Context(), req, c.redactor.url(req.URL).String())
+	resp, err := c.send(req.WithContext(ctx), v, options)
+	end(resp, err)
+	return resp, err
+}
+
+// send sends a request with or without payload.
+func (c *Client) send(req *http.Request, v any, options []RequestOption) (*http.Response, error) {
+	req = req.WithContext(policies.WithRetryCounter(req.Context()))
+	o := c.requestOptions(req.Context(), options)
+	allowsRetry, err := c.allowsRetry(req)
+	if err != nil {
+		return nil, &HttpError{
+			Url:    req.URL.String(),
+			Method: req.Method,
+			Err:    err,
+		}
+	}
+	if !allowsRetry {
+		o.maxRetries = 0
+		o.checkRetryPolicy = policies.DoNotRetry
+	}
+	// Hedged calls run concurrently, so they can't share
+	// a body that can't be read again from the start.
+	if mayHedge(req, o) && !isRewindable(req) {
+		o.hedgeDelay, o.maxHedges = 0, 0
+	}
+	retryableReq, err := newRetryableRequest(req, o.maxRetries > 0)
+	if err != nil {
+		return nil, &HttpError{
+			Url:    req.URL.String(),
+			Method: req.Method,
+			Err:    err,
+		}
+	}
+	if c.breaker != nil {
+		if err := c.breaker.Allow(c.circuitBreakerKey(req)); err != nil {
+			return nil, &HttpError{
+				Url:    req.URL.String(),
+				Method: req.Method,
+				Err:    err,
+			}
+		}
+	}
+	if c.retryBudget != nil {
+		c.retryBudget.RecordRequest()
+	}
+	resp, err := c.do(retryableReq, v, o)
 	if err != nil {
 		return resp, err
 	}
@@ -198,12 +433,17 @@ func (c *Client) sendRequest(req *http.Request, v any) (*http.Response, error) {
 }
 
 // SendRequest sends an HTTP request and returns an HTTP response.
-func (c *Client) SendRequest(req *http.Request) (*http.Response, error) {
-	return c.sendRequest(req, nil)
+// The given options override the Client settings for this request only.
+func (c *Client) SendRequest(req *http.Request, options ...RequestOption) (*http.Response, error) {
+	return c.sendRequest(req, nil, options)
 }
 
 // SendRequestAndUnmarshallJsonResponse sends an HTTP request \
 // and unmarshalls the responseBody to the given interface.
-func (c *Client) SendRequestAndUnmarshallJsonResponse(req *http.Request, v any) (*http.Response, error) {
-	return c.sendRequest(req, v)
+// The responseBody is decoded by the codec registered for its
+// Content-Type, falling back to JSON.
+// The given options override the Client settings for this request only.
+func (c *Client) SendRequestAndUnmarshallJsonResponse(req *http.Request, v any,
+	options ...RequestOption) (*http.Response, error) {
+	return c.sendRequest(req, v, options)
 }
@@ -185,14 +185,7 @@ func TestSendRequest(t *testing.T) {
 				expectedDump := "POST /some/path HTTP/1.1\r\nHost: localhost\r\nUser-Agent: Go-http-client/1.1\r\nContent-Length: 0\r\nAccept-Encoding: gzip\r\n\r\n"
 				require.Equal(t, expectedDump, string(dump))
 			},
-			retryableHttpClientDoMock: func(retryableHttpClient *retryablehttp.Client,
-				req *retryablehttp.Request) (*http.Response, error) {
-				resp := &http.Response{
-					StatusCode: http.StatusOK,
-					Body:       io.NopCloser(bytes.NewReader([]byte(""))),
-				}
-				return resp, nil
-			},
+			retryableHttpClientDoMock: singleAttemptMock(""),
 		},
 		{
 			name: "error when dumping request",
@@ -202,14 +195,7 @@ func TestSendRequest(t *testing.T) {
 			requestDumpLogger: func(dump []byte) {
 				require.Equal(t, "", string(dump))
 			},
-			retryableHttpClientDoMock: func(retryableHttpClient *retryablehttp.Client,
-				req *retryablehttp.Request) (*http.Response, error) {
-				resp := &http.Response{
-					StatusCode: http.StatusOK,
-					Body:       io.NopCloser(bytes.NewReader([]byte(""))),
-				}
-				return resp, nil
-			},
+			retryableHttpClientDoMock: singleAttemptMock(""),
 		},
 		{
 			name: "dumping non-nil response",
@@ -217,14 +203,7 @@ func TestSendRequest(t *testing.T) {
 				expectedDump := "HTTP/0.0 200 OK\r\nContent-Length: 0\r\n\r\n"
 				require.Equal(t, expectedDump, string(dump))
 			},
-			retryableHttpClientDoMock: func(retryableHttpClient *retryablehttp.Client,
-				req *retryablehttp.Request) (*http.Response, error) {
-				resp := &http.Response{
-					StatusCode: http.StatusOK,
-					Body:       io.NopCloser(bytes.NewReader([]byte(`{"message":"ok"}`))),
-				}
-				return resp, nil
-			},
+			retryableHttpClientDoMock: singleAttemptMock(`{"message":"ok"}`),
 		},
 		{
 			name: "dumping nil response",
@@ -241,14 +220,7 @@ func TestSendRequest(t *testing.T) {
 			responseDumpLogger: func(dump []byte) {
 				require.Equal(t, "", string(dump))
 			},
-			retryableHttpClientDoMock: func(retryableHttpClient *retryablehttp.Client,
-				req *retryablehttp.Request) (*http.Response, error) {
-				resp := &http.Response{
-					StatusCode: http.StatusOK,
-					Body:       io.NopCloser(bytes.NewReader([]byte(""))),
-				}
-				return resp, nil
-			},
+			retryableHttpClientDoMock: singleAttemptMock(""),
 		},
 		{
 			name: "unsuccessful response with responseBody",
@@ -303,6 +275,8 @@ func TestSendRequest(t *testing.T) {
 				`httpStatus: [ no status ] responseBody: [  ] error: [ random error ]`),
 		},
 	}
+	originalRetryableHttpClientDo := retryableHttpClientDo
+	defer func() { retryableHttpClientDo = originalRetryableHttpClientDo }()
 	originalIoReadAll := ioReadAll
 	originalDumpRequestOut := dumpRequestOut
 	originalDumpResponse := dumpResponse
@@ -422,6 +396,8 @@ func TestSendRequestAndUnmarshallJsonResponse(t *testing.T) {
 				`httpStatus: [ no status ] responseBody: [  ] error: [ random error ]`),
 		},
 	}
+	originalRetryableHttpClientDo := retryableHttpClientDo
+	defer func() { retryableHttpClientDo = originalRetryableHttpClientDo }()
 	originalIoReadAll := ioReadAll
 	originalJsonDecode := jsonDecode
 	for _, tc := range testCases {
@@ -448,6 +424,26 @@ func TestSendRequestAndUnmarshallJsonResponse(t *testing.T) {
 	}
 }
 
+// singleAttemptMock returns a mock that simulates a single successful
+// attempt getting the given response body, calling the log hooks along.
+func singleAttemptMock(body string) func(retryableHttpClient *retryablehttp.Client,
+	req *retryablehttp.Request) (*http.Response, error) {
+	return func(retryableHttpClient *retryablehttp.Client,
+		req *retryablehttp.Request) (*http.Response, error) {
+		if retryableHttpClient.RequestLogHook != nil {
+			retryableHttpClient.RequestLogHook(nil, req.Request, 0)
+		}
+		resp := &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(bytes.NewReader([]byte(body))),
+		}
+		if retryableHttpClient.ResponseLogHook != nil {
+			retryableHttpClient.ResponseLogHook(nil, resp)
+		}
+		return resp, nil
+	}
+}
+
 func handleIoReadAllMock(mocked ioReadAllMock, original ioReadAllMock) {
 	if mocked != nil {
 		ioReadAll = mocked
@@ -479,3 +475,31 @@ func handleDumpResponse(mocked dumpResponseMock, original dumpResponseMock) {
 		dumpResponse = original
 	}
 }
+
+func TestWithBackoff(t *testing.T) {
+	var attempts int
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer svr.Close()
+	var attemptNums []int
+	client := New(
+		WithMaxRetries(2),
+		WithRetryWaitMin(time.Millisecond),
+		WithRetryWaitMax(time.Second),
+		WithCheckRetryPolicy(policies.StatusCodes(http.StatusServiceUnavailable)),
+		WithBackoff(func(min, max time.Duration, attemptNum int, resp *http.Response) time.Duration {
+			require.Equal(t, time.Millisecond, min)
+			require.Equal(t, time.Second, max)
+			attemptNums = append(attemptNums, attemptNum)
+			return 0
+		}),
+	)
+	req, err := NewRequest(context.TODO(), http.MethodGet, svr.URL)
+	require.NoError(t, err)
+	_, err = client.SendRequest(req)
+	require.NotNil(t, err)
+	require.Equal(t, 3, attempts)
+	require.Equal(t, []int{0, 1}, attemptNums)
+}
@@ -0,0 +1,73 @@
+package httpclient
+
+import (
+	"crypto/rand"
+	"fmt"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+// IdempotencyKeyHeader is the header that makes retrying
+// a non-idempotent request safe.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// For ease of unit testing.
+// Declaring these functions as global variables
+// makes it easy to mock them.
+var (
+	randRead          = rand.Read
+	newIdempotencyKey = func() (string, error) {
+		const uuidLength = 16
+		b := make([]byte, uuidLength)
+		if _, err := randRead(b); err != nil {
+			return "", errors.Wrap(err, "generating idempotency key")
+		}
+		// Version 4, variant 10 UUID, as defined by RFC 4122.
+		b[6] = (b[6] & 0x0f) | 0x40
+		b[8] = (b[8] & 0x3f) | 0x80
+		return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+	}
+)
+
+// isIdempotent reports whether requests with the given
+// method can be safely sent more than once.
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPatch:
+		return false
+	default:
+		return true
+	}
+}
+
+// allowsRetry reports whether the given request may be retried.
+// When idempotent retries are enabled, POST and PATCH requests are
+// only retried when they carry an Idempotency-Key header, which is
+// generated when asked to. The generated key is added to a copy of
+// the request headers, so every attempt carries the same key, while
+// sending the same request again generates a new one.
+func (c *Client) allowsRetry(req *http.Request) (bool, error) {
+	if !c.idempotentRetries || isIdempotent(req.Method) {
+		return true, nil
+	}
+	if req.Header.Get(IdempotencyKeyHeader) != "" {
+		return true, nil
+	}
+	if !c.generateIdempotencyKeys {
+		return false, nil
+	}
+	key, err := newIdempotencyKey()
+	if err != nil {
+		return false, err
+	}
+	// The request is a shallow copy of the caller's one, so the headers
+	// are cloned, leaving the caller's request without the key.
+	header := req.Header.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+	header.Set(IdempotencyKeyHeader, key)
+	req.Header = header
+	return true, nil
+}
@@ -0,0 +1,133 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tiagomelo/go-retryable-httpclient/httpclient/retry/policies"
+)
+
+func TestWithIdempotentRetries(t *testing.T) {
+	testCases := []struct {
+		name             string
+		method           string
+		headers          map[string]string
+		generateKeys     bool
+		expectedAttempts int
+		expectedKey      string
+	}{
+		{
+			name:             "safe method is retried",
+			method:           http.MethodGet,
+			expectedAttempts: 3,
+		},
+		{
+			name:             "idempotent method is retried",
+			method:           http.MethodPut,
+			expectedAttempts: 3,
+		},
+		{
+			name:             "POST without key is not retried",
+			method:           http.MethodPost,
+			expectedAttempts: 1,
+		},
+		{
+			name:             "PATCH without key is not retried",
+			method:           http.MethodPatch,
+			expectedAttempts: 1,
+		},
+		{
+			name:             "POST with key is retried",
+			method:           http.MethodPost,
+			headers:          map[string]string{IdempotencyKeyHeader: "some-key"},
+			expectedAttempts: 3,
+			expectedKey:      "some-key",
+		},
+		{
+			name:             "POST with generated key is retried",
+			method:           http.MethodPost,
+			generateKeys:     true,
+			expectedAttempts: 3,
+		},
+	}
+	uuidRegexp := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				mu   sync.Mutex
+				keys []string
+			)
+			svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				defer mu.Unlock()
+				keys = append(keys, r.Header.Get(IdempotencyKeyHeader))
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}))
+			defer svr.Close()
+			client := New(
+				WithMaxRetries(2),
+				WithCheckRetryPolicy(policies.StatusCodes(http.StatusServiceUnavailable)),
+				WithIdempotentRetries(tc.generateKeys),
+			)
+			req, err := NewJsonRequestWithHeaders(context.TODO(), tc.method, svr.URL, `{"key":"value"}`, tc.headers)
+			require.NoError(t, err)
+			_, err = client.SendRequest(req)
+			require.NotNil(t, err)
+			require.Len(t, keys, tc.expectedAttempts)
+			for _, key := range keys {
+				require.Equal(t, keys[0], key)
+			}
+			if tc.generateKeys {
+				require.Regexp(t, uuidRegexp, keys[0])
+			} else {
+				require.Equal(t, tc.expectedKey, keys[0])
+			}
+		})
+	}
+}
+
+func TestIdempotencyKeyGenerationError(t *testing.T) {
+	originalRandRead := randRead
+	defer func() { randRead = originalRandRead }()
+	randRead = func(b []byte) (int, error) {
+		return 0, errors.New("random error")
+	}
+	client := New(WithMaxRetries(2), WithIdempotentRetries(true))
+	req, err := NewJsonRequest(context.TODO(), http.MethodPost, "http://localhost/some/path", `{"key":"value"}`)
+	require.NoError(t, err)
+	_, err = client.SendRequest(req)
+	require.NotNil(t, err)
+	require.Equal(t, `request to http://localhost/some/path failed. `+
+		`httpStatus: [ no status ] responseBody: [  ] `+
+		`error: [ generating idempotency key: random error ]`, err.Error())
+}
+
+func TestGeneratedIdempotencyKeyIsNotSharedAcrossCalls(t *testing.T) {
+	var keys []string
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get(IdempotencyKeyHeader))
+	}))
+	defer svr.Close()
+	client := New(
+		WithMaxRetries(1),
+		WithCheckRetryPolicy(policies.Eof),
+		WithIdempotentRetries(true),
+	)
+	req, err := NewRequest(context.TODO(), http.MethodPost, svr.URL)
+	require.NoError(t, err)
+	for i := 0; i < 2; i++ {
+		_, err = client.SendRequest(req)
+		require.NoError(t, err)
+	}
+	require.Empty(t, req.Header.Get(IdempotencyKeyHeader))
+	require.Len(t, keys, 2)
+	require.NotEmpty(t, keys[0])
+	require.NotEmpty(t, keys[1])
+	require.NotEqual(t, keys[0], keys[1])
+}
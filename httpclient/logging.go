@@ -0,0 +1,136 @@
+package httpclient
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+)
+
+// attemptLogger logs a structured record for
+// every attempt of a single call.
+type attemptLogger struct {
+	logger     *slog.Logger
+	redactor   *redactor
+	req        *http.Request
+	maxRetries int
+	attemptNum int
+	start      time.Time
+}
+
+// requestLogHook wraps the given request log hook,
+// keeping track of the attempt being made.
+func (l *attemptLogger) requestLogHook(hook retryablehttp.RequestLogHook) retryablehttp.RequestLogHook {
+	return func(logger retryablehttp.Logger, req *http.Request, attemptNum int) {
+		l.attemptNum = attemptNum
+		l.start = now()
+		if hook != nil {
+			hook(logger, req, attemptNum)
+		}
+	}
+}
+
+// checkRetry wraps the given check retry policy, logging
+// the outcome of every attempt along with the retry decision.
+func (l *attemptLogger) checkRetry(checkRetry retryablehttp.CheckRetry) retryablehttp.CheckRetry {
+	return func(ctx context.Context, resp *http.Response, err error) (bool, error) {
+		retry, checkErr := checkRetry(ctx, resp, err)
+		l.log(ctx, resp, err, checkErr, retry && l.attemptNum < l.maxRetries)
+		return retry, checkErr
+	}
+}
+
+// log logs the outcome of an attempt. Successful attempts are
+// logged at debug level, attempts that are retried at warn level
+// and failed attempts that are given up on at error level.
+func (l *attemptLogger) log(ctx context.Context, resp *http.Response, err, checkErr error, retry bool) {
+	attrs := []slog.Attr{
+		slog.String("method", l.req.Method),
+		slog.String("url", l.redactor.url(l.req.URL).String()),
+		slog.Int("attempt", l.attemptNum+1),
+		slog.Duration("duration", now().Sub(l.start)),
+		slog.Bool("retry", retry),
+	}
+	if resp != nil {
+		attrs = append(attrs, slog.Int("status", resp.StatusCode))
+	}
+	if err == nil {
+		err = checkErr
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", l.redactor.text(err.Error())))
+	}
+	failed := err != nil || (resp != nil && resp.StatusCode >= http.StatusBadRequest)
+	level, msg := slog.LevelDebug, "http attempt succeeded"
+	switch {
+	case retry:
+		level, msg = slog.LevelWarn, "http attempt failed, retrying"
+	case failed:
+		level, msg = slog.LevelError, "http attempt failed"
+	}
+	l.logger.LogAttrs(ctx, level, msg, attrs...)
+}
+
+// redactingPrinter wraps the default logger of go-retryablehttp,
+// which writes to stderr, masking the redacted values of the urls
+// within every line it logs.
+type redactingPrinter struct {
+	logger   retryablehttp.Logger
+	redactor *redactor
+}
+
+// Printf implements retryablehttp.Logger.
+func (l *redactingPrinter) Printf(format string, args ...any) {
+	l.logger.Printf("%s", l.redactor.text(fmt.Sprintf(format, args...)))
+}
+
+// redactingLogger is the logger go-retryablehttp logs through, which
+// masks the redacted values of the urls it logs, including those
+// within messages and errors, e.g. *url.Error.
+type redactingLogger struct {
+	logger   *slog.Logger
+	redactor *redactor
+}
+
+// redact returns the given key-value pairs with urls redacted.
+func (l *redactingLogger) redact(keysAndValues []any) []any {
+	redacted := make([]any, len(keysAndValues))
+	for i, v := range keysAndValues {
+		switch value := v.(type) {
+		case *url.URL:
+			if value != nil {
+				v = l.redactor.url(value).String()
+			}
+		case error:
+			v = l.redactor.text(value.Error())
+		case string:
+			v = l.redactor.text(value)
+		}
+		redacted[i] = v
+	}
+	return redacted
+}
+
+// Error implements retryablehttp.LeveledLogger.
+func (l *redactingLogger) Error(msg string, keysAndValues ...any) {
+	l.logger.Error(l.redactor.text(msg), l.redact(keysAndValues)...)
+}
+
+// Info implements retryablehttp.LeveledLogger.
+func (l *redactingLogger) Info(msg string, keysAndValues ...any) {
+	l.logger.Info(l.redactor.text(msg), l.redact(keysAndValues)...)
+}
+
+// Debug implements retryablehttp.LeveledLogger.
+func (l *redactingLogger) Debug(msg string, keysAndValues ...any) {
+	l.logger.Debug(l.redactor.text(msg), l.redact(keysAndValues)...)
+}
+
+// Warn implements retryablehttp.LeveledLogger.
+func (l *redactingLogger) Warn(msg string, keysAndValues ...any) {
+	l.logger.Warn(l.redactor.text(msg), l.redact(keysAndValues)...)
+}
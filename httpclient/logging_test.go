@@ -0,0 +1,102 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tiagomelo/go-retryable-httpclient/httpclient/retry/policies"
+)
+
+// attemptRecords returns the attempt records found in the given
+// json log output, without the attributes that vary between runs.
+func attemptRecords(t *testing.T, output *bytes.Buffer) []map[string]any {
+	var records []map[string]any
+	dec := json.NewDecoder(output)
+	for dec.More() {
+		var record map[string]any
+		require.NoError(t, dec.Decode(&record))
+		if _, ok := record["attempt"]; !ok {
+			continue
+		}
+		require.Contains(t, record, "duration")
+		delete(record, "time")
+		delete(record, "duration")
+		delete(record, "url")
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestWithLogger(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		statuses = []int{http.StatusServiceUnavailable, http.StatusOK}
+	)
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		w.WriteHeader(statuses[0])
+		statuses = statuses[1:]
+	}))
+	defer svr.Close()
+	var output bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&output, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	client := New(
+		WithMaxRetries(1),
+		WithRetryWaitMin(time.Millisecond),
+		WithRetryWaitMax(time.Millisecond),
+		WithCheckRetryPolicy(policies.StatusCodes(http.StatusServiceUnavailable)),
+		WithLogger(logger),
+	)
+	req, err := NewRequest(context.TODO(), http.MethodGet, svr.URL)
+	require.NoError(t, err)
+	_, err = client.SendRequest(req)
+	require.NoError(t, err)
+	// go-retryablehttp logs through the given logger.
+	require.Contains(t, output.String(), `"msg":"performing request"`)
+	require.Equal(t, []map[string]any{
+		{
+			"level":   "WARN",
+			"msg":     "http attempt failed, retrying",
+			"method":  "GET",
+			"attempt": float64(1),
+			"retry":   true,
+			"status":  float64(http.StatusServiceUnavailable),
+		},
+		{
+			"level":   "DEBUG",
+			"msg":     "http attempt succeeded",
+			"method":  "GET",
+			"attempt": float64(2),
+			"retry":   false,
+			"status":  float64(http.StatusOK),
+		},
+	}, attemptRecords(t, &output))
+}
+
+func TestWithLoggerGivingUp(t *testing.T) {
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	svr.Close()
+	var output bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&output, nil))
+	client := New(WithLogger(logger))
+	req, err := NewRequest(context.TODO(), http.MethodPost, svr.URL)
+	require.NoError(t, err)
+	_, err = client.SendRequest(req)
+	require.NotNil(t, err)
+	records := attemptRecords(t, &output)
+	require.Len(t, records, 1)
+	require.Equal(t, "ERROR", records[0]["level"])
+	require.Equal(t, "http attempt failed", records[0]["msg"])
+	require.Equal(t, "POST", records[0]["method"])
+	require.Equal(t, false, records[0]["retry"])
+	require.Contains(t, records[0]["error"], "connection refused")
+}
@@ -0,0 +1,81 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/tiagomelo/go-retryable-httpclient/httpclient/ratelimit"
+)
+
+// StatusClass is an errors.Is target matching any HttpError
+// whose status code belongs to the class, e.g. StatusClass(4)
+// matches 4xx responses.
+type StatusClass int
+
+// Status classes of unsuccessful responses.
+var (
+	// ErrClientError matches HttpErrors of 4xx responses.
+	ErrClientError error = StatusClass(4)
+	// ErrServerError matches HttpErrors of 5xx responses.
+	ErrServerError error = StatusClass(5)
+)
+
+// Error returns the name of the class. It implements the error interface.
+func (c StatusClass) Error() string {
+	return fmt.Sprintf("%dxx response", int(c))
+}
+
+// matches reports whether the given status code belongs to the class.
+func (c StatusClass) matches(statusCode int) bool {
+	const classDivisor = 100
+	return statusCode > 0 && statusCode/classDivisor == int(c)
+}
+
+// IsClientError reports whether err, or any error it wraps,
+// is an HttpError of a 4xx response.
+func IsClientError(err error) bool {
+	return errors.Is(err, ErrClientError)
+}
+
+// IsServerError reports whether err, or any error it wraps,
+// is an HttpError of a 5xx response.
+func IsServerError(err error) bool {
+	return errors.Is(err, ErrServerError)
+}
+
+// IsStatus reports whether err, or any error it wraps, is an
+// HttpError of a response with any of the given status codes.
+func IsStatus(err error, codes ...int) bool {
+	var httpErr *HttpError
+	if !errors.As(err, &httpErr) {
+		return false
+	}
+	for _, code := range codes {
+		if httpErr.StatusCode == code {
+			return true
+		}
+	}
+	return false
+}
+
+// IsTimeout reports whether err, or any error it wraps, is a
+// timeout: a deadline exceeded, a network timeout, or a retry
+// or a rate limiter wait given up for not fitting the deadline.
+func IsTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, ErrAttemptExceedsDeadline) ||
+		errors.Is(err, ErrRetryAfterExceedsDeadline) ||
+		errors.Is(err, ratelimit.ErrWaitExceedsDeadline) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// IsCanceled reports whether err, or any error it wraps,
+// is a context cancellation.
+func IsCanceled(err error) bool {
+	return errors.Is(err, context.Canceled)
+}
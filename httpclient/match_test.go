@@ -0,0 +1,131 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatusMatchers(t *testing.T) {
+	testCases := []struct {
+		name                string
+		err                 error
+		expectedClientError bool
+		expectedServerError bool
+		expectedIsStatus    bool
+	}{
+		{
+			name:                "client error",
+			err:                 &HttpError{StatusCode: http.StatusNotFound},
+			expectedClientError: true,
+			expectedIsStatus:    true,
+		},
+		{
+			name:                "wrapped client error",
+			err:                 fmt.Errorf("getting user: %w", &HttpError{StatusCode: http.StatusGone}),
+			expectedClientError: true,
+			expectedIsStatus:    true,
+		},
+		{
+			name:                "server error",
+			err:                 &HttpError{StatusCode: http.StatusBadGateway},
+			expectedServerError: true,
+		},
+		{
+			name: "without status",
+			err:  &HttpError{Err: errors.New("random error")},
+		},
+		{
+			name: "other error",
+			err:  errors.New("random error"),
+		},
+		{
+			name: "nil error",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expectedClientError, IsClientError(tc.err))
+			require.Equal(t, tc.expectedServerError, IsServerError(tc.err))
+			require.Equal(t, tc.expectedIsStatus, IsStatus(tc.err, http.StatusNotFound, http.StatusGone))
+		})
+	}
+}
+
+func TestIsTimeoutAndIsCanceled(t *testing.T) {
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer svr.Close()
+	testCases := []struct {
+		name             string
+		err              func() error
+		expectedTimeout  bool
+		expectedCanceled bool
+	}{
+		{
+			name: "attempt timeout",
+			err: func() error {
+				client := New(WithAttemptTimeout(10 * time.Millisecond))
+				req, err := NewRequest(context.TODO(), http.MethodGet, svr.URL)
+				require.NoError(t, err)
+				_, err = client.SendRequest(req)
+				return err
+			},
+			expectedTimeout: true,
+		},
+		{
+			name: "context deadline",
+			err: func() error {
+				ctx, cancel := context.WithTimeout(context.TODO(), 10*time.Millisecond)
+				defer cancel()
+				req, err := NewRequest(ctx, http.MethodGet, svr.URL)
+				require.NoError(t, err)
+				_, err = New().SendRequest(req)
+				return err
+			},
+			expectedTimeout: true,
+		},
+		{
+			name: "context canceled",
+			err: func() error {
+				ctx, cancel := context.WithCancel(context.TODO())
+				time.AfterFunc(10*time.Millisecond, cancel)
+				req, err := NewRequest(ctx, http.MethodGet, svr.URL)
+				require.NoError(t, err)
+				_, err = New().SendRequest(req)
+				return err
+			},
+			expectedCanceled: true,
+		},
+		{
+			name: "retry given up for the deadline",
+			err: func() error {
+				return fmt.Errorf("wrapped: %w", &HttpError{Err: ErrAttemptExceedsDeadline})
+			},
+			expectedTimeout: true,
+		},
+		{
+			name: "other error",
+			err: func() error {
+				return &HttpError{StatusCode: http.StatusGatewayTimeout}
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.err()
+			require.Equal(t, tc.expectedTimeout, IsTimeout(err))
+			require.Equal(t, tc.expectedCanceled, IsCanceled(err))
+		})
+	}
+}
@@ -1,10 +1,16 @@
 package httpclient
 
 import (
+	"log/slog"
 	"net/http"
 	"time"
 
 	"github.com/hashicorp/go-retryablehttp"
+	"github.com/tiagomelo/go-retryable-httpclient/httpclient/adaptive"
+	"github.com/tiagomelo/go-retryable-httpclient/httpclient/bulkhead"
+	"github.com/tiagomelo/go-retryable-httpclient/httpclient/circuitbreaker"
+	"github.com/tiagomelo/go-retryable-httpclient/httpclient/ratelimit"
+	"github.com/tiagomelo/go-retryable-httpclient/httpclient/retry/budget"
 )
 
 // Option represents a Client option.
@@ -24,6 +30,24 @@ func WithTimeout(t time.Duration) Option {
 	}
 }
 
+// WithAttemptTimeout limits the time each attempt may take.
+// Unlike WithTimeout, it also applies to a client
+// provided through WithHttpClient.
+func WithAttemptTimeout(t time.Duration) Option {
+	return func(c *Client) {
+		c.attemptTimeout = t
+	}
+}
+
+// WithTotalTimeout limits the time a whole call may take, including
+// every attempt and every wait between them. A retry is not attempted
+// when it can't finish within the time left.
+func WithTotalTimeout(t time.Duration) Option {
+	return func(c *Client) {
+		c.totalTimeout = t
+	}
+}
+
 // WithMaxIdleConns defines the maximum number of idle (keep-alive)
 // connections across all hosts.
 func WithMaxIdleConns(n int) Option {
@@ -77,9 +101,178 @@ func WithCheckRetryPolicy(checkRetryPolicy retryablehttp.CheckRetry) Option {
 	}
 }
 
+// WithBackoff specifies the strategy for how long to wait
+// between retries, bounded by WithRetryWaitMin and WithRetryWaitMax.
+// Strategies can be picked from the backoff package.
+func WithBackoff(backoff retryablehttp.Backoff) Option {
+	return func(c *Client) {
+		c.backoff = backoff
+	}
+}
+
+// WithRetryAfter makes the client wait as long as the server asks
+// through the Retry-After or X-RateLimit-Reset headers of 429 and 503
+// responses, instead of its computed backoff. The wait is bounded
+// by maxWait or, when it is zero, by WithRetryWaitMax, or else by
+// one minute. The client gives up early when the wait would outlast
+// the request context deadline.
+func WithRetryAfter(maxWait time.Duration) Option {
+	return func(c *Client) {
+		c.honorRetryAfter = true
+		c.retryAfterMaxWait = maxWait
+	}
+}
+
+// WithIdempotentRetries makes the client retry POST and PATCH
+// requests only when they carry an Idempotency-Key header, while
+// requests with any other method are retried normally. When
+// generateKeys is true, a key is generated for POST and PATCH
+// requests that don't carry one, and reused across all attempts.
+func WithIdempotentRetries(generateKeys bool) Option {
+	return func(c *Client) {
+		c.idempotentRetries = true
+		c.generateIdempotencyKeys = generateKeys
+	}
+}
+
+// WithCircuitBreaker makes requests go through the given circuit
+// breaker, which short-circuits them while their circuit is open.
+// Circuits are keyed by host, unless WithCircuitBreakerKey is provided.
+// An attempt counts as failed when the check retry policy would retry
+// it or when it got an error.
+func WithCircuitBreaker(breaker *circuitbreaker.CircuitBreaker) Option {
+	return func(c *Client) {
+		c.breaker = breaker
+	}
+}
+
+// WithCircuitBreakerKey specifies a function that returns the key
+// of the circuit a request goes through.
+func WithCircuitBreakerKey(key func(req *http.Request) string) Option {
+	return func(c *Client) {
+		c.breakerKey = key
+	}
+}
+
+// WithRateLimit limits the rate of requests sent by the client
+// to requestsPerSecond on average, with bursts of up to burst requests.
+// Every attempt, including retries, waits for its turn, giving up
+// when the request context is done or its deadline can't be met.
+// A requestsPerSecond that isn't greater than zero means no limit.
+func WithRateLimit(requestsPerSecond float64, burst int) Option {
+	return func(c *Client) {
+		c.limiter = nil
+		if requestsPerSecond > 0 {
+			c.limiter = ratelimit.NewLimiter(requestsPerSecond, burst)
+		}
+	}
+}
+
+// WithRateLimitPerHost is like WithRateLimit, but the rate
+// of requests is limited for each host separately. It can be
+// combined with WithRateLimit.
+func WithRateLimitPerHost(requestsPerSecond float64, burst int) Option {
+	return func(c *Client) {
+		c.hostLimiter = nil
+		if requestsPerSecond > 0 {
+			c.hostLimiter = ratelimit.NewKeyedLimiter(requestsPerSecond, burst)
+		}
+	}
+}
+
+// WithRetryBudget makes retries draw from the given retry budget,
+// which can be shared across clients. When it is exhausted, the
+// client gives up retrying right away, returning an error that
+// wraps budget.ErrExhausted.
+func WithRetryBudget(retryBudget *budget.Budget) Option {
+	return func(c *Client) {
+		c.retryBudget = retryBudget
+	}
+}
+
+// WithBulkhead bounds the number of requests in flight to each
+// host, along with the number of requests waiting for their turn,
+// through the given bulkhead. Every attempt, including retries,
+// waits for its turn and stays in flight until its response body
+// is closed. Rejected attempts fail with an error wrapping
+// a *bulkhead.RejectedError.
+func WithBulkhead(b *bulkhead.Bulkhead) Option {
+	return func(c *Client) {
+		c.bulkhead = b
+	}
+}
+
+// WithAdaptiveConcurrency bounds the number of requests in flight
+// to each host through the given adaptive concurrency limiter, which
+// discovers the limit from the latency and outcome of every attempt,
+// including retries. Errors, 429 and 5xx responses shrink the limit.
+// An attempt stays in flight until its response body is closed, and
+// attempts over the limit fail with an error wrapping
+// adaptive.ErrLimitExceeded.
+func WithAdaptiveConcurrency(limiter *adaptive.Limiter) Option {
+	return func(c *Client) {
+		c.concurrencyLimiter = limiter
+	}
+}
+
+// WithHedging makes the client send a duplicate of a request, up to
+// maxHedges times, every time delay elapses without a response, and
+// return whichever response arrives first. The other calls are canceled
+// and their responses closed. Each of them goes through the whole retry
+// loop. Only GET, HEAD, OPTIONS, TRACE, PUT and DELETE requests are
+// hedged, as long as their body, if any, can be read again from the
+// start. Hedges draw from the retry budget, if any.
+func WithHedging(delay time.Duration, maxHedges int) Option {
+	return func(c *Client) {
+		c.hedgeDelay = delay
+		c.maxHedges = maxHedges
+	}
+}
+
+// WithErrorBody specifies a function that returns a pointer to
+// the error type that 4xx and 5xx response bodies are decoded into,
+// e.g. func() error { return new(APIError) }. The decoded value
+// is stored in HttpError.ErrorBody and is reachable through errors.As.
+// Bodies are decoded by the codec registered for their Content-Type.
+func WithErrorBody(newErrorBody func() error) Option {
+	return func(c *Client) {
+		c.newErrorBody = newErrorBody
+	}
+}
+
+// WithLogger specifies a logger that receives a structured record for
+// every attempt, with its method, url, attempt number, duration, status,
+// retry decision and error. Successful attempts are logged at debug
+// level, attempts that are retried at warn level and failed attempts
+// that are given up on at error level. go-retryablehttp logs through
+// it as well, instead of its default logger writing to stderr. Either
+// way, the values given to WithRedactedQueryParams are masked.
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *Client) {
+		c.logger = logger
+	}
+}
+
+// WithHooks specifies functions called along the attempts of
+// every call: before and after every attempt, before every retry
+// and when a call fails for good.
+func WithHooks(hooks Hooks) Option {
+	return func(c *Client) {
+		c.hooks = hooks
+	}
+}
+
+// WithTracer specifies a tracer that traces every call
+// along with each of its attempts.
+func WithTracer(tracer Tracer) Option {
+	return func(c *Client) {
+		c.tracer = tracer
+	}
+}
+
 // WithRequestDumpLogger specifies a function that receives
-// the request dump along its body (optionally) for
-// logging purposes.
+// the request dump of every attempt along its body (optionally)
+// for logging purposes.
 func WithRequestDumpLogger(requestDumpLogger func(dump []byte), dumpRequestBody bool) Option {
 	return func(c *Client) {
 		c.requestDumpLogger = requestDumpLogger
@@ -88,11 +281,38 @@ func WithRequestDumpLogger(requestDumpLogger func(dump []byte), dumpRequestBody
 }
 
 // WithResponseDumpLogger specifies a function that receives
-// the response dump along its body (optionally) for
-// logging purposes.
+// the response dump of every attempt along its body (optionally)
+// for logging purposes.
 func WithResponseDumpLogger(responseDumpLogger func(dump []byte), dumpResponseBody bool) Option {
 	return func(c *Client) {
 		c.responseDumpLogger = responseDumpLogger
 		c.dumpResponseBody = dumpResponseBody
 	}
 }
+
+// WithRedactedHeaders specifies headers whose values are replaced by
+// RedactedValue in dumps and logs, in addition to Authorization,
+// Cookie and Set-Cookie, which are always redacted.
+func WithRedactedHeaders(names ...string) Option {
+	return func(c *Client) {
+		c.redactor.addHeaders(names)
+	}
+}
+
+// WithRedactedQueryParams specifies query parameters whose values
+// are replaced by RedactedValue in dumps and logs.
+func WithRedactedQueryParams(names ...string) Option {
+	return func(c *Client) {
+		c.redactor.addQueryParams(names)
+	}
+}
+
+// WithRedactedJsonFields specifies json fields whose values are
+// replaced by RedactedValue in dumped bodies. Fields are given as
+// dot-separated paths from the root of the document, e.g. "user.password".
+// Arrays are traversed, so that "items.token" masks the token of each item.
+func WithRedactedJsonFields(paths ...string) Option {
+	return func(c *Client) {
+		c.redactor.addJsonFields(paths)
+	}
+}
@@ -0,0 +1,105 @@
+package httpclient
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// ProblemContentType is the content type of
+// problem details, as defined by RFC 9457.
+const ProblemContentType = "application/problem+json"
+
+// ProblemDetails holds the problem details of an unsuccessful
+// response, as defined by RFC 9457. Members other than
+// the standard ones are kept in Extensions.
+type ProblemDetails struct {
+	Type       string
+	Title      string
+	Status     int
+	Detail     string
+	Instance   string
+	Extensions map[string]any
+}
+
+// problemMembers are the standard members of problem details.
+type problemMembers struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail"`
+	Instance string `json:"instance"`
+}
+
+// UnmarshalJSON decodes problem details, keeping
+// non-standard members in Extensions.
+func (p *ProblemDetails) UnmarshalJSON(data []byte) error {
+	var members problemMembers
+	if err := json.Unmarshal(data, &members); err != nil {
+		return err
+	}
+	var all map[string]any
+	if err := json.Unmarshal(data, &all); err != nil {
+		return err
+	}
+	for _, name := range []string{"type", "title", "status", "detail", "instance"} {
+		delete(all, name)
+	}
+	*p = ProblemDetails{
+		Type:     members.Type,
+		Title:    members.Title,
+		Status:   members.Status,
+		Detail:   members.Detail,
+		Instance: members.Instance,
+	}
+	if len(all) > 0 {
+		p.Extensions = all
+	}
+	return nil
+}
+
+// Error returns the title and detail of the problem, along with
+// its type unless it is about:blank. It implements the error interface.
+func (p *ProblemDetails) Error() string {
+	var sb strings.Builder
+	sb.WriteString(p.Title)
+	if p.Detail != "" {
+		if sb.Len() > 0 {
+			sb.WriteString(": ")
+		}
+		sb.WriteString(p.Detail)
+	}
+	if p.Type != "" && p.Type != "about:blank" {
+		if sb.Len() > 0 {
+			sb.WriteString(" ")
+		}
+		sb.WriteString("(" + p.Type + ")")
+	}
+	return sb.String()
+}
+
+// isProblem reports whether the given response carries problem details.
+func isProblem(resp *http.Response) bool {
+	if resp == nil {
+		return false
+	}
+	mediaType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	return err == nil && mediaType == ProblemContentType
+}
+
+// decodeProblemDetails decodes the problem details carried by the
+// unsuccessful response wrapped by the given error, storing them in
+// the error. Bodies that can't be decoded are left as they are,
+// in HttpError.Body.
+func decodeProblemDetails(err error, resp *http.Response) {
+	httpErr, ok := err.(*HttpError)
+	if !ok || httpErr.Body == "" || !isProblem(resp) {
+		return
+	}
+	problem := new(ProblemDetails)
+	if err := json.Unmarshal([]byte(httpErr.Body), problem); err != nil {
+		return
+	}
+	httpErr.Problem = problem
+}
@@ -0,0 +1,155 @@
+package httpclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tiagomelo/go-retryable-httpclient/httpclient/retry/policies"
+)
+
+func TestProblemDetailsUnmarshalJSON(t *testing.T) {
+	var p ProblemDetails
+	err := json.Unmarshal([]byte(`{
+		"type": "https://example.com/probs/out-of-credit",
+		"title": "You do not have enough credit.",
+		"status": 403,
+		"detail": "Your current balance is 30, but that costs 50.",
+		"instance": "/account/12345/msgs/abc",
+		"balance": 30
+	}`), &p)
+	require.NoError(t, err)
+	require.Equal(t, ProblemDetails{
+		Type:       "https://example.com/probs/out-of-credit",
+		Title:      "You do not have enough credit.",
+		Status:     http.StatusForbidden,
+		Detail:     "Your current balance is 30, but that costs 50.",
+		Instance:   "/account/12345/msgs/abc",
+		Extensions: map[string]any{"balance": float64(30)},
+	}, p)
+	require.NotNil(t, json.Unmarshal([]byte(`{"status":"403"}`), &p))
+}
+
+func TestProblemDetailsError(t *testing.T) {
+	testCases := []struct {
+		name           string
+		problem        *ProblemDetails
+		expectedOutput string
+	}{
+		{
+			name: "title, detail and type",
+			problem: &ProblemDetails{
+				Type:   "https://example.com/probs/out-of-credit",
+				Title:  "You do not have enough credit.",
+				Detail: "Your current balance is 30, but that costs 50.",
+			},
+			expectedOutput: "You do not have enough credit.: Your current balance is 30, " +
+				"but that costs 50. (https://example.com/probs/out-of-credit)",
+		},
+		{
+			name: "about:blank type is omitted",
+			problem: &ProblemDetails{
+				Type:  "about:blank",
+				Title: "Not Found",
+			},
+			expectedOutput: "Not Found",
+		},
+		{
+			name:           "only detail",
+			problem:        &ProblemDetails{Detail: "no such user"},
+			expectedOutput: "no such user",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expectedOutput, tc.problem.Error())
+		})
+	}
+}
+
+func TestProblemDetailsResponse(t *testing.T) {
+	testCases := []struct {
+		name            string
+		contentType     string
+		body            string
+		expectedProblem *ProblemDetails
+		expectedError   string
+	}{
+		{
+			name:        "problem details",
+			contentType: "application/problem+json; charset=utf-8",
+			body:        `{"title":"Not Found","status":404,"detail":"no such user"}`,
+			expectedProblem: &ProblemDetails{
+				Title:  "Not Found",
+				Status: http.StatusNotFound,
+				Detail: "no such user",
+			},
+			expectedError: "httpStatus: [ 404 ] problem: [ Not Found: no such user ] error: [ <nil> ]",
+		},
+		{
+			name:          "invalid problem details",
+			contentType:   "application/problem+json",
+			body:          `not found`,
+			expectedError: "httpStatus: [ 404 ] responseBody: [ not found ] error: [ <nil> ]",
+		},
+		{
+			name:          "other content type",
+			contentType:   "application/json",
+			body:          `{"title":"Not Found","status":404}`,
+			expectedError: `httpStatus: [ 404 ] responseBody: [ {"title":"Not Found","status":404} ] error: [ <nil> ]`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", tc.contentType)
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(tc.body))
+			}))
+			defer svr.Close()
+			client := New()
+			req, err := NewRequest(context.TODO(), http.MethodGet, svr.URL)
+			require.NoError(t, err)
+			_, err = client.SendRequest(req)
+			require.NotNil(t, err)
+			require.Equal(t, "request to "+svr.URL+" failed. "+tc.expectedError, err.Error())
+			var problem *ProblemDetails
+			require.Equal(t, tc.expectedProblem != nil, errors.As(err, &problem))
+			require.Equal(t, tc.expectedProblem, problem)
+		})
+	}
+}
+
+func TestProblemDetailsResponseWhenGivingUpRetrying(t *testing.T) {
+	var attempts int
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.Header().Set("Content-Type", ProblemContentType)
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte(`{"title":"Service Unavailable","status":503,"detail":"under maintenance"}`))
+	}))
+	defer svr.Close()
+	client := New(
+		WithMaxRetries(1),
+		WithRetryWaitMin(time.Millisecond),
+		WithRetryWaitMax(time.Millisecond),
+		WithCheckRetryPolicy(policies.StatusCodes(http.StatusServiceUnavailable)),
+	)
+	req, err := NewRequest(context.TODO(), http.MethodGet, svr.URL)
+	require.NoError(t, err)
+	_, err = client.SendRequest(req)
+	require.Equal(t, 2, attempts)
+	var problem *ProblemDetails
+	require.True(t, errors.As(err, &problem))
+	require.Equal(t, &ProblemDetails{
+		Title:  "Service Unavailable",
+		Status: http.StatusServiceUnavailable,
+		Detail: "under maintenance",
+	}, problem)
+	require.Contains(t, err.Error(), "httpStatus: [ 503 ] problem: [ Service Unavailable: under maintenance ]")
+}
@@ -0,0 +1,60 @@
+package httpclient
+
+import (
+	"net/http"
+
+	"github.com/pkg/errors"
+	"github.com/tiagomelo/go-retryable-httpclient/httpclient/ratelimit"
+)
+
+// rateLimitedTransport is an http.RoundTripper that waits
+// on the client rate limiters before every attempt,
+// including the retries.
+type rateLimitedTransport struct {
+	next    http.RoundTripper
+	limiter *ratelimit.Limiter
+	perHost *ratelimit.KeyedLimiter
+}
+
+// RoundTrip waits on the rate limiters and sends the request.
+func (t *rateLimitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.wait(req); err != nil {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, errors.Wrap(err, "waiting for rate limiter")
+	}
+	return t.next.RoundTrip(req)
+}
+
+// wait blocks until the given request is allowed by the rate limiters.
+func (t *rateLimitedTransport) wait(req *http.Request) error {
+	if t.limiter != nil {
+		if err := t.limiter.Wait(req.Context()); err != nil {
+			return err
+		}
+	}
+	if t.perHost != nil {
+		return t.perHost.Wait(req.Context(), req.URL.Host)
+	}
+	return nil
+}
+
+// rateLimitedClient returns a copy of the given http client
+// whose transport waits on the client rate limiters.
+func (c *Client) rateLimitedClient(httpClient *http.Client) *http.Client {
+	if c.limiter == nil && c.hostLimiter == nil {
+		return httpClient
+	}
+	next := httpClient.Transport
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	hc := *httpClient
+	hc.Transport = &rateLimitedTransport{
+		next:    next,
+		limiter: c.limiter,
+		perHost: c.hostLimiter,
+	}
+	return &hc
+}
@@ -0,0 +1,144 @@
+// Package ratelimit provides token bucket rate limiters.
+// They can be used with httpclient.WithRateLimit and
+// httpclient.WithRateLimitPerHost.
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ErrWaitExceedsDeadline is returned when waiting for a token
+// would outlast the context deadline.
+var ErrWaitExceedsDeadline = errors.New("rate limiter wait exceeds context deadline")
+
+// For ease of unit testing.
+// Declaring these functions as global variables
+// makes it easy to mock them.
+var (
+	now = time.Now
+)
+
+// Limiter is a token bucket that is refilled at a given rate,
+// up to a given burst. It is safe for concurrent use.
+type Limiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// NewLimiter returns a new Limiter allowing requestsPerSecond
+// on average, with bursts of up to burst requests.
+// It panics when requestsPerSecond isn't greater than zero.
+func NewLimiter(requestsPerSecond float64, burst int) *Limiter {
+	mustBePositive(requestsPerSecond)
+	if burst < 1 {
+		burst = 1
+	}
+	return &Limiter{
+		rate:   requestsPerSecond,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   now(),
+	}
+}
+
+// mustBePositive panics when the given rate isn't greater than zero,
+// since such a rate never refills the bucket.
+func mustBePositive(requestsPerSecond float64) {
+	if !(requestsPerSecond > 0) {
+		panic("ratelimit: requestsPerSecond must be greater than zero")
+	}
+}
+
+// reserve takes a token, possibly going into debt,
+// and returns how long to wait before using it.
+func (l *Limiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	t := now()
+	l.tokens += t.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = t
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
+
+// cancel gives back a token taken by reserve but not used.
+func (l *Limiter) cancel() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.tokens++
+}
+
+// Wait blocks until a token is available or ctx is done.
+// It returns right away with ErrWaitExceedsDeadline when
+// the wait would outlast the ctx deadline.
+func (l *Limiter) Wait(ctx context.Context) error {
+	wait := l.reserve()
+	if wait == 0 {
+		return nil
+	}
+	if deadline, ok := ctx.Deadline(); ok && now().Add(wait).After(deadline) {
+		l.cancel()
+		return ErrWaitExceedsDeadline
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		l.cancel()
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// KeyedLimiter keeps one Limiter per key, e.g. per host.
+// It is safe for concurrent use.
+type KeyedLimiter struct {
+	mu                sync.Mutex
+	requestsPerSecond float64
+	burst             int
+	limiters          map[string]*Limiter
+}
+
+// NewKeyedLimiter returns a new KeyedLimiter whose limiters allow
+// requestsPerSecond on average, with bursts of up to burst requests.
+// It panics when requestsPerSecond isn't greater than zero.
+func NewKeyedLimiter(requestsPerSecond float64, burst int) *KeyedLimiter {
+	mustBePositive(requestsPerSecond)
+	return &KeyedLimiter{
+		requestsPerSecond: requestsPerSecond,
+		burst:             burst,
+		limiters:          make(map[string]*Limiter),
+	}
+}
+
+// Limiter returns the Limiter for the given key.
+func (k *KeyedLimiter) Limiter(key string) *Limiter {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	l, ok := k.limiters[key]
+	if !ok {
+		l = NewLimiter(k.requestsPerSecond, k.burst)
+		k.limiters[key] = l
+	}
+	return l
+}
+
+// Wait blocks until a token for the given key
+// is available or ctx is done.
+func (k *KeyedLimiter) Wait(ctx context.Context, key string) error {
+	return k.Limiter(key).Wait(ctx)
+}
@@ -0,0 +1,94 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimiterReserve(t *testing.T) {
+	originalNow := now
+	defer func() { now = originalNow }()
+	current := time.Date(2023, time.April, 9, 10, 0, 0, 0, time.UTC)
+	now = func() time.Time { return current }
+	l := NewLimiter(10, 2)
+	require.Equal(t, time.Duration(0), l.reserve())
+	require.Equal(t, time.Duration(0), l.reserve())
+	require.Equal(t, 100*time.Millisecond, l.reserve())
+	require.Equal(t, 200*time.Millisecond, l.reserve())
+	current = current.Add(time.Second)
+	require.Equal(t, time.Duration(0), l.reserve())
+	require.Equal(t, time.Duration(0), l.reserve())
+	require.Equal(t, 100*time.Millisecond, l.reserve())
+}
+
+func TestLimiterWait(t *testing.T) {
+	testCases := []struct {
+		name          string
+		ctx           func() (context.Context, context.CancelFunc)
+		expectedError error
+	}{
+		{
+			name: "waits for a token",
+			ctx: func() (context.Context, context.CancelFunc) {
+				return context.WithCancel(context.TODO())
+			},
+		},
+		{
+			name: "wait exceeds deadline",
+			ctx: func() (context.Context, context.CancelFunc) {
+				return context.WithTimeout(context.TODO(), 10*time.Millisecond)
+			},
+			expectedError: ErrWaitExceedsDeadline,
+		},
+		{
+			name: "canceled while waiting",
+			ctx: func() (context.Context, context.CancelFunc) {
+				ctx, cancel := context.WithCancel(context.TODO())
+				time.AfterFunc(10*time.Millisecond, cancel)
+				return ctx, cancel
+			},
+			expectedError: context.Canceled,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			l := NewLimiter(20, 1)
+			require.NoError(t, l.Wait(context.TODO()))
+			ctx, cancel := tc.ctx()
+			defer cancel()
+			start := time.Now()
+			err := l.Wait(ctx)
+			if tc.expectedError != nil {
+				require.ErrorIs(t, err, tc.expectedError)
+				require.Less(t, time.Since(start), 40*time.Millisecond)
+				// The token taken by the failed wait is given back.
+				require.InDelta(t, 50*time.Millisecond, l.reserve(), float64(20*time.Millisecond))
+				return
+			}
+			require.NoError(t, err)
+			require.GreaterOrEqual(t, time.Since(start), 40*time.Millisecond)
+		})
+	}
+}
+
+func TestKeyedLimiter(t *testing.T) {
+	k := NewKeyedLimiter(1, 1)
+	require.Same(t, k.Limiter("some-key"), k.Limiter("some-key"))
+	require.NotSame(t, k.Limiter("some-key"), k.Limiter("another-key"))
+	require.NoError(t, k.Wait(context.TODO(), "some-key"))
+	require.NoError(t, k.Wait(context.TODO(), "another-key"))
+	ctx, cancel := context.WithTimeout(context.TODO(), 10*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, k.Wait(ctx, "some-key"), ErrWaitExceedsDeadline)
+}
+
+func TestNonPositiveRatesAreRejected(t *testing.T) {
+	for _, requestsPerSecond := range []float64{0, -1, math.NaN()} {
+		require.Panics(t, func() { NewLimiter(requestsPerSecond, 1) })
+		require.Panics(t, func() { NewKeyedLimiter(requestsPerSecond, 1) })
+	}
+}
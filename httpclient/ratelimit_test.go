@@ -0,0 +1,112 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tiagomelo/go-retryable-httpclient/httpclient/ratelimit"
+	"github.com/tiagomelo/go-retryable-httpclient/httpclient/retry/policies"
+)
+
+// newFlakyServer returns a server that fails
+// every other request with 503 Service Unavailable.
+func newFlakyServer() *httptest.Server {
+	var (
+		mu       sync.Mutex
+		attempts int
+	)
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		attempts++
+		if attempts%2 == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+}
+
+func TestWithRateLimit(t *testing.T) {
+	testCases := []struct {
+		name        string
+		option      Option
+		minDuration time.Duration
+	}{
+		{
+			name:        "global limit accounts for retries",
+			option:      WithRateLimit(20, 1),
+			minDuration: 150 * time.Millisecond,
+		},
+		{
+			name:        "per-host limit",
+			option:      WithRateLimitPerHost(20, 1),
+			minDuration: 50 * time.Millisecond,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			svr1, svr2 := newFlakyServer(), newFlakyServer()
+			defer svr1.Close()
+			defer svr2.Close()
+			client := New(
+				WithMaxRetries(1),
+				WithRetryWaitMin(time.Millisecond),
+				WithRetryWaitMax(time.Millisecond),
+				WithCheckRetryPolicy(policies.StatusCodes(http.StatusServiceUnavailable)),
+				tc.option,
+			)
+			start := time.Now()
+			// Each call makes two attempts, the first one being retried.
+			for _, url := range []string{svr1.URL, svr2.URL} {
+				req, err := NewRequest(context.TODO(), http.MethodGet, url)
+				require.NoError(t, err)
+				_, err = client.SendRequest(req)
+				require.NoError(t, err)
+			}
+			require.GreaterOrEqual(t, time.Since(start), tc.minDuration)
+		})
+	}
+}
+
+func TestWithRateLimitRespectsContext(t *testing.T) {
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer svr.Close()
+	client := New(WithRateLimit(1, 1))
+	req, err := NewRequest(context.TODO(), http.MethodGet, svr.URL)
+	require.NoError(t, err)
+	_, err = client.SendRequest(req)
+	require.NoError(t, err)
+	ctx, cancel := context.WithTimeout(context.TODO(), 50*time.Millisecond)
+	defer cancel()
+	req, err = NewRequest(ctx, http.MethodGet, svr.URL)
+	require.NoError(t, err)
+	start := time.Now()
+	_, err = client.SendRequest(req)
+	require.NotNil(t, err)
+	require.True(t, strings.Contains(err.Error(), ratelimit.ErrWaitExceedsDeadline.Error()))
+	require.Less(t, time.Since(start), 50*time.Millisecond)
+}
+
+func TestWithNonPositiveRateLimits(t *testing.T) {
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer svr.Close()
+	var client *Client
+	require.NotPanics(t, func() {
+		client = New(
+			WithRateLimit(0, 1),
+			WithRateLimitPerHost(-1, 1),
+		)
+	})
+	require.Nil(t, client.limiter)
+	require.Nil(t, client.hostLimiter)
+	req, err := NewRequest(context.TODO(), http.MethodGet, svr.URL)
+	require.NoError(t, err)
+	resp, err := client.SendRequest(req)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+}
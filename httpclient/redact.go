@@ -0,0 +1,212 @@
+package httpclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// RedactedValue replaces the values that are redacted
+// from dumps and logs.
+const RedactedValue = "[REDACTED]"
+
+// defaultRedactedHeaders are the headers redacted by default.
+var defaultRedactedHeaders = []string{"Authorization", "Cookie", "Set-Cookie"}
+
+// urlPattern matches the urls found within
+// log messages and error messages.
+var urlPattern = regexp.MustCompile(`https?://[^\s"'<>]+`)
+
+// redactor masks the values of the configured headers, query
+// parameters and json fields before requests and responses
+// are dumped or logged.
+type redactor struct {
+	headers     map[string]bool
+	queryParams map[string]bool
+	jsonFields  [][]string
+}
+
+// newRedactor returns a redactor masking the default headers.
+func newRedactor() *redactor {
+	r := &redactor{
+		headers:     make(map[string]bool),
+		queryParams: make(map[string]bool),
+	}
+	r.addHeaders(defaultRedactedHeaders)
+	return r
+}
+
+// addHeaders adds the given headers to the redacted ones.
+func (r *redactor) addHeaders(names []string) {
+	for _, name := range names {
+		r.headers[http.CanonicalHeaderKey(name)] = true
+	}
+}
+
+// addQueryParams adds the given query parameters to the redacted ones.
+func (r *redactor) addQueryParams(names []string) {
+	for _, name := range names {
+		r.queryParams[name] = true
+	}
+}
+
+// addJsonFields adds the given json field paths to the redacted ones.
+func (r *redactor) addJsonFields(paths []string) {
+	for _, path := range paths {
+		r.jsonFields = append(r.jsonFields, strings.Split(path, "."))
+	}
+}
+
+// header returns a copy of the given header with
+// the values of the redacted headers masked.
+func (r *redactor) header(h http.Header) http.Header {
+	if h == nil {
+		return nil
+	}
+	redacted := h.Clone()
+	for name, values := range redacted {
+		if r.headers[http.CanonicalHeaderKey(name)] {
+			for i := range values {
+				values[i] = RedactedValue
+			}
+		}
+	}
+	return redacted
+}
+
+// url returns a copy of the given url with the password and
+// the values of the redacted query parameters masked.
+func (r *redactor) url(u *url.URL) *url.URL {
+	redacted := *u
+	if _, hasPassword := u.User.Password(); hasPassword {
+		redacted.User = url.UserPassword(u.User.Username(), RedactedValue)
+	}
+	if len(r.queryParams) == 0 || u.RawQuery == "" {
+		return &redacted
+	}
+	query := u.Query()
+	for name, values := range query {
+		if r.queryParams[name] {
+			for i := range values {
+				values[i] = RedactedValue
+			}
+		}
+	}
+	redacted.RawQuery = query.Encode()
+	return &redacted
+}
+
+// text returns the given text, e.g. an error message, with the
+// password and the values of the redacted query parameters of
+// the urls it contains masked.
+func (r *redactor) text(s string) string {
+	return urlPattern.ReplaceAllStringFunc(s, func(raw string) string {
+		u, err := url.Parse(raw)
+		if err != nil {
+			return raw
+		}
+		return r.url(u).String()
+	})
+}
+
+// body returns the given body with the values of the redacted json
+// fields masked. Bodies that aren't json are returned as they are.
+func (r *redactor) body(b []byte) []byte {
+	if len(r.jsonFields) == 0 {
+		return b
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return b
+	}
+	for _, path := range r.jsonFields {
+		redactJsonField(v, path)
+	}
+	redacted, err := json.Marshal(v)
+	if err != nil {
+		return b
+	}
+	return redacted
+}
+
+// redactJsonField masks the value of the field at the given path
+// within v. Arrays are traversed, so that the path applies to
+// each of their elements.
+func redactJsonField(v any, path []string) {
+	switch value := v.(type) {
+	case []any:
+		for _, element := range value {
+			redactJsonField(element, path)
+		}
+	case map[string]any:
+		field, ok := value[path[0]]
+		if !ok {
+			return
+		}
+		if len(path) == 1 {
+			value[path[0]] = RedactedValue
+			return
+		}
+		redactJsonField(field, path[1:])
+	}
+}
+
+// readBody reads the whole given body, returning its content along
+// with a body that replays it and still closes the original one.
+func readBody(body io.ReadCloser) ([]byte, io.ReadCloser, error) {
+	b, err := io.ReadAll(body)
+	if err != nil {
+		return nil, body, err
+	}
+	return b, struct {
+		io.Reader
+		io.Closer
+	}{bytes.NewReader(b), body}, nil
+}
+
+// request returns a copy of the given request to be dumped,
+// along with its body when withBody is true, with the redacted
+// values masked. The body of the given request is left unread.
+func (r *redactor) request(req *http.Request, withBody bool) (*http.Request, error) {
+	redacted := req.Clone(req.Context())
+	redacted.Header = r.header(req.Header)
+	redacted.URL = r.url(req.URL)
+	if !withBody || req.Body == nil || req.Body == http.NoBody {
+		return redacted, nil
+	}
+	b, body, err := readBody(req.Body)
+	req.Body = body
+	if err != nil {
+		return nil, err
+	}
+	b = r.body(b)
+	redacted.Body = io.NopCloser(bytes.NewReader(b))
+	redacted.ContentLength = int64(len(b))
+	return redacted, nil
+}
+
+// response returns a copy of the given response to be dumped,
+// along with its body when withBody is true, with the redacted
+// values masked. The body of the given response is left unread.
+func (r *redactor) response(resp *http.Response, withBody bool) (*http.Response, error) {
+	redacted := *resp
+	redacted.Header = r.header(resp.Header)
+	if !withBody || resp.Body == nil || resp.Body == http.NoBody {
+		return &redacted, nil
+	}
+	b, body, err := readBody(resp.Body)
+	resp.Body = body
+	if err != nil {
+		return nil, err
+	}
+	b = r.body(b)
+	redacted.Body = io.NopCloser(bytes.NewReader(b))
+	redacted.ContentLength = int64(len(b))
+	return &redacted, nil
+}
@@ -6,8 +6,10 @@ import (
 	"encoding/json"
 	"io"
 	"net/http"
+	"strings"
 
 	"github.com/pkg/errors"
+	"github.com/tiagomelo/go-retryable-httpclient/httpclient/codec"
 )
 
 // For ease of unit testing.
@@ -43,6 +45,40 @@ func NewRequestWithHeaders(ctx context.Context, method, url string,
 	return req, nil
 }
 
+// NewRequestWithBody returns an *http.Request whose body can be
+// replayed on retries. Supported payloads are []byte, string and
+// io.ReadSeeker. The caller remains responsible for closing
+// an io.ReadSeeker payload.
+func NewRequestWithBody(ctx context.Context, method, url string, payload any) (*http.Request, error) {
+	var body io.Reader
+	switch p := payload.(type) {
+	case nil:
+	case []byte:
+		body = bytes.NewReader(p)
+	case string:
+		body = strings.NewReader(p)
+	case io.ReadSeeker:
+		body = p
+	default:
+		return nil, errors.Errorf("unsupported request body type %T", payload)
+	}
+	req, err := newRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating request")
+	}
+	if seeker, isSeeker := payload.(io.ReadSeeker); isSeeker && req.GetBody == nil {
+		getBody := seekerBody(seeker)
+		req.GetBody = func() (io.ReadCloser, error) {
+			r, err := getBody()
+			if err != nil {
+				return nil, err
+			}
+			return io.NopCloser(r), nil
+		}
+	}
+	return req, nil
+}
+
 // NewJsonRequest returns an *http.Request with a json encoded body.
 func NewJsonRequest(ctx context.Context, method,
 	url string, data any) (*http.Request, error) {
@@ -74,8 +110,33 @@ func NewJsonRequestWithHeaders(ctx context.Context, method, url string,
 	return req, nil
 }
 
-// body returns the appropriate payload.
+// NewEncodedRequest returns an *http.Request with a body encoded
+// by the given codec, e.g. codec.XML, and the matching Content-Type
+// header. A string payload is sent as is.
+func NewEncodedRequest(ctx context.Context, method, url string,
+	data any, c codec.Codec) (*http.Request, error) {
+	body, err := encodedBody(data, c.Encode)
+	if err != nil {
+		return nil, err
+	}
+	req, err := newRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating request")
+	}
+	req.Header = http.Header{
+		"Content-Type": {c.ContentType()},
+	}
+	return req, nil
+}
+
+// body returns the appropriate json payload.
 func body(data any) (io.Reader, error) {
+	return encodedBody(data, jsonEncode)
+}
+
+// encodedBody returns the appropriate payload,
+// encoded by the given function.
+func encodedBody(data any, encode func(w io.Writer, data any) error) (io.Reader, error) {
 	var body io.Reader
 	var j []byte
 	switch p := data.(type) {
@@ -86,7 +147,7 @@ func body(data any) (io.Reader, error) {
 		body = bytes.NewBuffer(j)
 	default:
 		var buf bytes.Buffer
-		if err := jsonEncode(&buf, data); err != nil {
+		if err := encode(&buf, data); err != nil {
 			return nil, errors.Wrap(err, "encoding request payload")
 		}
 		body = &buf
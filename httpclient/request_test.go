@@ -41,6 +41,7 @@ func TestNewRequest(t *testing.T) {
 		},
 	}
 	originalNewRequestWithContext = newRequestWithContext
+	defer handleNewRequestMock(nil, originalNewRequestWithContext)
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			handleNewRequestMock(tc.mockNewRequest, originalNewRequestWithContext)
@@ -85,6 +86,7 @@ func TestNewRequestWithHeaders(t *testing.T) {
 			expectedError: errors.New("creating request: random error"),
 		},
 	}
+	defer handleNewRequestMock(nil, originalNewRequestWithContext)
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			handleNewRequestMock(tc.mockNewRequest, originalNewRequestWithContext)
@@ -142,6 +144,7 @@ func TestNewJsonRequestWithHeaders(t *testing.T) {
 			expectedError: errors.New("creating request: random error"),
 		},
 	}
+	defer handleNewRequestMock(nil, originalNewRequestWithContext)
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			handleNewRequestMock(tc.mockNewRequest, originalNewRequestWithContext)
@@ -233,6 +236,8 @@ func TestNewJsonRequest(t *testing.T) {
 		},
 	}
 
+	defer handleNewRequestMock(nil, originalNewRequestWithContext)
+	defer handleJsonEncodeMock(nil, originalJsonEncode)
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			handleJsonEncodeMock(tc.mockJsonEncode, originalJsonEncode)
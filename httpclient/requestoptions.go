@@ -0,0 +1,131 @@
+package httpclient
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+)
+
+// RequestOption represents a per-request option that
+// overrides the Client settings for a single call.
+type RequestOption func(*requestOptions)
+
+// requestOptions holds the settings in effect for a single call.
+type requestOptions struct {
+	maxRetries       int
+	checkRetryPolicy retryablehttp.CheckRetry
+	attemptTimeout   time.Duration
+	totalTimeout     time.Duration
+	dumpLogging      bool
+	decode           func(r io.Reader, v any) error
+	newErrorBody     func() error
+	hedgeDelay       time.Duration
+	maxHedges        int
+}
+
+// requestOptionsKey is the context key under which
+// request options are stored.
+type requestOptionsKey struct{}
+
+// ContextWithRequestOptions returns a copy of ctx carrying the given
+// request options, which are applied to any request sent with it.
+// Options passed directly to SendRequest take precedence.
+func ContextWithRequestOptions(ctx context.Context, options ...RequestOption) context.Context {
+	existing, _ := ctx.Value(requestOptionsKey{}).([]RequestOption)
+	all := make([]RequestOption, 0, len(existing)+len(options))
+	all = append(all, existing...)
+	all = append(all, options...)
+	return context.WithValue(ctx, requestOptionsKey{}, all)
+}
+
+// WithRequestMaxRetries overrides the maximum number of retries.
+func WithRequestMaxRetries(n int) RequestOption {
+	return func(o *requestOptions) {
+		o.maxRetries = n
+	}
+}
+
+// WithRequestCheckRetryPolicy overrides the policy for handling retries.
+func WithRequestCheckRetryPolicy(checkRetryPolicy retryablehttp.CheckRetry) RequestOption {
+	return func(o *requestOptions) {
+		o.checkRetryPolicy = checkRetryPolicy
+	}
+}
+
+// WithRequestAttemptTimeout overrides the timeout of each attempt.
+func WithRequestAttemptTimeout(t time.Duration) RequestOption {
+	return func(o *requestOptions) {
+		o.attemptTimeout = t
+	}
+}
+
+// WithRequestTotalTimeout overrides the timeout of the whole
+// call, including every attempt and every wait between them.
+func WithRequestTotalTimeout(t time.Duration) RequestOption {
+	return func(o *requestOptions) {
+		o.totalTimeout = t
+	}
+}
+
+// WithRequestHedging overrides the hedging delay and the maximum
+// number of hedges. A zero maxHedges turns hedging off.
+func WithRequestHedging(delay time.Duration, maxHedges int) RequestOption {
+	return func(o *requestOptions) {
+		o.hedgeDelay = delay
+		o.maxHedges = maxHedges
+	}
+}
+
+// WithRequestDumpLogging turns request and response dump
+// logging on or off.
+func WithRequestDumpLogging(enabled bool) RequestOption {
+	return func(o *requestOptions) {
+		o.dumpLogging = enabled
+	}
+}
+
+// WithRequestDecoder overrides the function used to decode the
+// response body, which is otherwise picked from the codecs registered
+// for the response Content-Type, falling back to JSON.
+func WithRequestDecoder(decode func(r io.Reader, v any) error) RequestOption {
+	return func(o *requestOptions) {
+		o.decode = decode
+	}
+}
+
+// WithRequestErrorBody overrides the function that returns
+// the value unsuccessful response bodies are decoded into.
+func WithRequestErrorBody(newErrorBody func() error) RequestOption {
+	return func(o *requestOptions) {
+		o.newErrorBody = newErrorBody
+	}
+}
+
+// requestOptions returns the settings in effect for the given
+// request: the Client settings, overridden by the options carried
+// by the request context, overridden by the given options.
+func (c *Client) requestOptions(ctx context.Context, options []RequestOption) *requestOptions {
+	o := &requestOptions{
+		maxRetries:       c.maxRetries,
+		checkRetryPolicy: c.checkRetryPolicy,
+		attemptTimeout:   c.httpClient.Timeout,
+		totalTimeout:     c.totalTimeout,
+		dumpLogging:      true,
+		newErrorBody:     c.newErrorBody,
+		hedgeDelay:       c.hedgeDelay,
+		maxHedges:        c.maxHedges,
+	}
+	if c.attemptTimeout > 0 {
+		o.attemptTimeout = c.attemptTimeout
+	}
+	fromContext, _ := ctx.Value(requestOptionsKey{}).([]RequestOption)
+	for _, option := range fromContext {
+		option(o)
+	}
+	for _, option := range options {
+		option(o)
+	}
+	return o
+}
@@ -0,0 +1,134 @@
+package httpclient
+
+import (
+	"context"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tiagomelo/go-retryable-httpclient/httpclient/retry/policies"
+)
+
+func TestRequestOptionsOverrideRetries(t *testing.T) {
+	testCases := []struct {
+		name             string
+		ctxOptions       []RequestOption
+		options          []RequestOption
+		expectedAttempts int
+	}{
+		{
+			name:             "client settings",
+			expectedAttempts: 3,
+		},
+		{
+			name:             "max retries",
+			options:          []RequestOption{WithRequestMaxRetries(0)},
+			expectedAttempts: 1,
+		},
+		{
+			name:             "check retry policy",
+			options:          []RequestOption{WithRequestCheckRetryPolicy(policies.DoNotRetry)},
+			expectedAttempts: 1,
+		},
+		{
+			name:             "options attached to the context",
+			ctxOptions:       []RequestOption{WithRequestMaxRetries(1)},
+			expectedAttempts: 2,
+		},
+		{
+			name:             "options passed directly take precedence",
+			ctxOptions:       []RequestOption{WithRequestMaxRetries(1)},
+			options:          []RequestOption{WithRequestMaxRetries(4)},
+			expectedAttempts: 5,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				mu       sync.Mutex
+				attempts int
+			)
+			svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				defer mu.Unlock()
+				attempts++
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}))
+			defer svr.Close()
+			client := New(
+				WithMaxRetries(2),
+				WithCheckRetryPolicy(policies.StatusCodes(http.StatusServiceUnavailable)),
+			)
+			ctx := ContextWithRequestOptions(context.TODO(), tc.ctxOptions...)
+			req, err := NewRequest(ctx, http.MethodGet, svr.URL)
+			require.NoError(t, err)
+			_, err = client.SendRequest(req, tc.options...)
+			require.NotNil(t, err)
+			require.Equal(t, tc.expectedAttempts, attempts)
+		})
+	}
+}
+
+func TestRequestOptionsOverrideAttemptTimeout(t *testing.T) {
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer svr.Close()
+	client := New(WithTimeout(time.Minute))
+	req, err := NewRequest(context.TODO(), http.MethodGet, svr.URL)
+	require.NoError(t, err)
+	start := time.Now()
+	_, err = client.SendRequest(req, WithRequestAttemptTimeout(10*time.Millisecond))
+	require.NotNil(t, err)
+	require.Less(t, time.Since(start), time.Second)
+	require.Equal(t, time.Minute, client.httpClient.Timeout)
+}
+
+func TestRequestOptionsOverrideDumpLogging(t *testing.T) {
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer svr.Close()
+	var dumps int
+	logDump := func(dump []byte) { dumps++ }
+	client := New(WithRequestDumpLogger(logDump, false), WithResponseDumpLogger(logDump, false))
+	req, err := NewRequest(context.TODO(), http.MethodGet, svr.URL)
+	require.NoError(t, err)
+	_, err = client.SendRequest(req, WithRequestDumpLogging(false))
+	require.NoError(t, err)
+	require.Zero(t, dumps)
+	_, err = client.SendRequest(req)
+	require.NoError(t, err)
+	require.Equal(t, 2, dumps)
+}
+
+func TestRequestOptionsOverrideDecoder(t *testing.T) {
+	type xmlType struct {
+		Key string `xml:"key"`
+	}
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := io.WriteString(w, `<xmlType><key>value</key></xmlType>`)
+		require.NoError(t, err)
+	}))
+	defer svr.Close()
+	client := New()
+	req, err := NewRequest(context.TODO(), http.MethodGet, svr.URL)
+	require.NoError(t, err)
+	var data xmlType
+	_, err = client.SendRequestAndUnmarshallJsonResponse(req, &data,
+		WithRequestDecoder(func(r io.Reader, v any) error {
+			return xml.NewDecoder(r).Decode(v)
+		}))
+	require.NoError(t, err)
+	require.Equal(t, "value", data.Key)
+	_, err = client.SendRequestAndUnmarshallJsonResponse(req, &data)
+	require.NotNil(t, err)
+	require.True(t, strings.Contains(err.Error(), "decoding response"))
+}
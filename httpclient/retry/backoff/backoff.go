@@ -0,0 +1,107 @@
+// Package backoff provides strategies for how long to wait between retries.
+// All of them can be used with httpclient.WithBackoff.
+package backoff
+
+import (
+	"math"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// For ease of unit testing.
+// Declaring these functions as global variables
+// makes it easy to mock them.
+var (
+	randFloat64 = lockedRandFloat64(rand.New(rand.NewSource(time.Now().UnixNano())))
+)
+
+// lockedRandFloat64 makes the given source safe for concurrent use.
+func lockedRandFloat64(r *rand.Rand) func() float64 {
+	var mu sync.Mutex
+	return func() float64 {
+		mu.Lock()
+		defer mu.Unlock()
+		return r.Float64()
+	}
+}
+
+// ceiling returns the longest wait allowed, which is max
+// unless it is lower than min.
+func ceiling(min, max time.Duration) time.Duration {
+	if max < min {
+		return min
+	}
+	return max
+}
+
+// capped caps the given wait, expressed as a float
+// to avoid overflows, to the longest wait allowed.
+func capped(wait float64, min, max time.Duration) time.Duration {
+	limit := ceiling(min, max)
+	if wait >= float64(limit) {
+		return limit
+	}
+	return time.Duration(wait)
+}
+
+// between returns a random duration between lower and upper.
+func between(lower, upper time.Duration) time.Duration {
+	return lower + time.Duration(randFloat64()*float64(upper-lower))
+}
+
+// exponential returns min * 2^attemptNum capped at max.
+func exponential(min, max time.Duration, attemptNum int) time.Duration {
+	const base = 2
+	return capped(math.Pow(base, float64(attemptNum))*float64(min), min, max)
+}
+
+// Constant always waits min.
+func Constant(min, max time.Duration, attemptNum int, resp *http.Response) time.Duration {
+	return min
+}
+
+// Linear waits min, 2*min, 3*min and so on, up to max.
+func Linear(min, max time.Duration, attemptNum int, resp *http.Response) time.Duration {
+	return capped(float64(attemptNum+1)*float64(min), min, max)
+}
+
+// Fibonacci waits min, min, 2*min, 3*min, 5*min and so on, up to max.
+func Fibonacci(min, max time.Duration, attemptNum int, resp *http.Response) time.Duration {
+	previous, current := 0.0, 1.0
+	for i := 0; i < attemptNum && current*float64(min) < float64(max); i++ {
+		previous, current = current, previous+current
+	}
+	return capped(current*float64(min), min, max)
+}
+
+// ExponentialFullJitter waits a random duration between zero
+// and min * 2^attemptNum, capped at max.
+func ExponentialFullJitter(min, max time.Duration, attemptNum int, resp *http.Response) time.Duration {
+	return between(0, exponential(min, max, attemptNum))
+}
+
+// ExponentialEqualJitter waits half of min * 2^attemptNum, capped at max,
+// plus a random duration between zero and the other half.
+func ExponentialEqualJitter(min, max time.Duration, attemptNum int, resp *http.Response) time.Duration {
+	const halves = 2
+	half := exponential(min, max, attemptNum) / halves
+	return between(half, half*halves)
+}
+
+// DecorrelatedJitter is a stateless approximation of decorrelated
+// jitter. Every call draws a fresh chain of attemptNum+1 random waits,
+// each between min and three times the one before it, capped at max,
+// and returns the last one. The wait therefore doesn't depend on the
+// wait actually used before the previous attempt, but it keeps no
+// state and is safe to share across requests.
+func DecorrelatedJitter(min, max time.Duration, attemptNum int, resp *http.Response) time.Duration {
+	const growth = 3
+	wait := min
+	for i := 0; i <= attemptNum; i++ {
+		upper := float64(wait) * growth
+		wait = capped(float64(min)+randFloat64()*(upper-float64(min)), min, max)
+	}
+	return wait
+}
@@ -0,0 +1,144 @@
+package backoff
+
+import (
+	"math"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackoff(t *testing.T) {
+	const (
+		min = 100 * time.Millisecond
+		max = time.Second
+	)
+	testCases := []struct {
+		name          string
+		backoff       retryablehttp.Backoff
+		min           time.Duration
+		max           time.Duration
+		random        float64
+		expectedWaits []time.Duration
+	}{
+		{
+			name:          "constant",
+			backoff:       Constant,
+			min:           min,
+			max:           max,
+			expectedWaits: []time.Duration{min, min, min, min},
+		},
+		{
+			name:    "linear",
+			backoff: Linear,
+			min:     min,
+			max:     250 * time.Millisecond,
+			expectedWaits: []time.Duration{
+				100 * time.Millisecond,
+				200 * time.Millisecond,
+				250 * time.Millisecond,
+				250 * time.Millisecond,
+			},
+		},
+		{
+			name:    "fibonacci",
+			backoff: Fibonacci,
+			min:     min,
+			max:     max,
+			expectedWaits: []time.Duration{
+				100 * time.Millisecond,
+				100 * time.Millisecond,
+				200 * time.Millisecond,
+				300 * time.Millisecond,
+				500 * time.Millisecond,
+				800 * time.Millisecond,
+				time.Second,
+				time.Second,
+			},
+		},
+		{
+			name:    "exponential with full jitter",
+			backoff: ExponentialFullJitter,
+			min:     min,
+			max:     max,
+			random:  0.5,
+			expectedWaits: []time.Duration{
+				50 * time.Millisecond,
+				100 * time.Millisecond,
+				200 * time.Millisecond,
+				400 * time.Millisecond,
+				500 * time.Millisecond,
+			},
+		},
+		{
+			name:    "exponential with equal jitter",
+			backoff: ExponentialEqualJitter,
+			min:     min,
+			max:     max,
+			random:  0.5,
+			expectedWaits: []time.Duration{
+				75 * time.Millisecond,
+				150 * time.Millisecond,
+				300 * time.Millisecond,
+				600 * time.Millisecond,
+				750 * time.Millisecond,
+			},
+		},
+		{
+			name:    "decorrelated jitter",
+			backoff: DecorrelatedJitter,
+			min:     min,
+			max:     max,
+			random:  0.5,
+			expectedWaits: []time.Duration{
+				200 * time.Millisecond,
+				350 * time.Millisecond,
+				575 * time.Millisecond,
+				912500 * time.Microsecond,
+				time.Second,
+			},
+		},
+		{
+			name:          "max lower than min",
+			backoff:       ExponentialFullJitter,
+			min:           min,
+			random:        1,
+			expectedWaits: []time.Duration{min, min},
+		},
+	}
+	originalRandFloat64 := randFloat64
+	defer func() { randFloat64 = originalRandFloat64 }()
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			randFloat64 = func() float64 { return tc.random }
+			for attemptNum, expectedWait := range tc.expectedWaits {
+				wait := tc.backoff(tc.min, tc.max, attemptNum, new(http.Response))
+				require.Equal(t, expectedWait, wait, "attempt %d", attemptNum)
+			}
+		})
+	}
+}
+
+func TestBackoffDoesNotOverflow(t *testing.T) {
+	backoffs := []retryablehttp.Backoff{
+		Linear,
+		Fibonacci,
+		ExponentialFullJitter,
+		ExponentialEqualJitter,
+		DecorrelatedJitter,
+	}
+	for _, backoff := range backoffs {
+		wait := backoff(time.Second, math.MaxInt64, 100, nil)
+		require.Positive(t, wait)
+	}
+}
+
+func TestJitterSpreadsWaits(t *testing.T) {
+	waits := make(map[time.Duration]struct{})
+	for i := 0; i < 10; i++ {
+		waits[ExponentialFullJitter(time.Second, time.Minute, 3, nil)] = struct{}{}
+	}
+	require.Greater(t, len(waits), 1)
+}
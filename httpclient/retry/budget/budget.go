@@ -0,0 +1,150 @@
+// Package budget provides a retry budget, which caps the retries
+// sent by a client to a fraction of its recent requests, so that
+// an upstream outage doesn't multiply the traffic sent to it.
+// It can be used with httpclient.WithRetryBudget.
+package budget
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ErrExhausted is returned when a retry is refused
+// because the retry budget is exhausted.
+var ErrExhausted = errors.New("retry budget exhausted")
+
+// For ease of unit testing.
+// Declaring these functions as global variables
+// makes it easy to mock them.
+var (
+	now = time.Now
+)
+
+// Default settings.
+const (
+	DefaultRatio               = 0.1
+	DefaultMinRetriesPerSecond = 10
+	DefaultWindow              = 10 * time.Second
+)
+
+// Settings configures a Budget.
+type Settings struct {
+	// Ratio is the fraction of the recent requests that may be retried,
+	// e.g. 0.1 allows one retry for every ten requests.
+	// Defaults to DefaultRatio.
+	Ratio float64
+	// MinRetriesPerSecond is the number of retries per second allowed
+	// regardless of the ratio, so that a client sending few requests
+	// can still retry them. Defaults to DefaultMinRetriesPerSecond.
+	MinRetriesPerSecond int
+	// Window is how far back requests and retries are remembered.
+	// It is rounded up to whole seconds. Defaults to DefaultWindow.
+	Window time.Duration
+}
+
+// Stats holds the state of a Budget.
+type Stats struct {
+	// Requests is the number of requests within the window.
+	Requests int
+	// Retries is the number of retries within the window.
+	Retries int
+	// Available is the number of retries currently allowed.
+	Available int
+	// Rejected is the number of retries refused since the Budget was created.
+	Rejected int
+}
+
+// bucket counts the requests and retries of a single second.
+type bucket struct {
+	second   int64
+	requests int
+	retries  int
+}
+
+// Budget keeps track of the requests and retries within a sliding
+// window, allowing retries as long as they don't exceed the given
+// ratio of requests plus the minimum retries per second.
+// It is safe for concurrent use.
+type Budget struct {
+	settings Settings
+	mu       sync.Mutex
+	buckets  []bucket
+	rejected int
+}
+
+// New returns a new Budget.
+func New(settings Settings) *Budget {
+	if settings.Ratio <= 0 {
+		settings.Ratio = DefaultRatio
+	}
+	if settings.MinRetriesPerSecond <= 0 {
+		settings.MinRetriesPerSecond = DefaultMinRetriesPerSecond
+	}
+	if settings.Window <= 0 {
+		settings.Window = DefaultWindow
+	}
+	seconds := int(math.Ceil(settings.Window.Seconds()))
+	return &Budget{
+		settings: settings,
+		buckets:  make([]bucket, seconds),
+	}
+}
+
+// current returns the bucket of the current second, resetting it
+// when it was last used for a second that is out of the window.
+// It must be called with the lock held.
+func (b *Budget) current() *bucket {
+	second := now().Unix()
+	bk := &b.buckets[second%int64(len(b.buckets))]
+	if bk.second != second {
+		*bk = bucket{second: second}
+	}
+	return bk
+}
+
+// stats returns the state of the budget.
+// It must be called with the lock held.
+func (b *Budget) stats() Stats {
+	oldest := now().Unix() - int64(len(b.buckets))
+	s := Stats{Rejected: b.rejected}
+	for _, bk := range b.buckets {
+		if bk.second > oldest {
+			s.Requests += bk.requests
+			s.Retries += bk.retries
+		}
+	}
+	allowed := b.settings.Ratio*float64(s.Requests) +
+		float64(b.settings.MinRetriesPerSecond*len(b.buckets))
+	s.Available = int(math.Max(0, math.Floor(allowed)-float64(s.Retries)))
+	return s
+}
+
+// RecordRequest records a request, making room for further retries.
+func (b *Budget) RecordRequest() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.current().requests++
+}
+
+// AllowRetry reports whether a retry is allowed,
+// recording it when it is.
+func (b *Budget) AllowRetry() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.stats().Available == 0 {
+		b.rejected++
+		return false
+	}
+	b.current().retries++
+	return true
+}
+
+// Stats returns the state of the budget.
+func (b *Budget) Stats() Stats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.stats()
+}
@@ -0,0 +1,83 @@
+package budget
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBudget(t *testing.T) {
+	type step struct {
+		advance         time.Duration
+		requests        int
+		retries         int
+		expectedAllowed int
+		expectedStats   Stats
+	}
+	testCases := []struct {
+		name     string
+		settings Settings
+		steps    []step
+	}{
+		{
+			name:     "minimum retries per second",
+			settings: Settings{MinRetriesPerSecond: 1, Window: 2 * time.Second},
+			steps: []step{
+				{retries: 3, expectedAllowed: 2, expectedStats: Stats{Retries: 2, Rejected: 1}},
+			},
+		},
+		{
+			name:     "retries are a ratio of requests",
+			settings: Settings{Ratio: 0.5, MinRetriesPerSecond: 1, Window: time.Second},
+			steps: []step{
+				{requests: 4, expectedStats: Stats{Requests: 4, Available: 3}},
+				{retries: 4, expectedAllowed: 3, expectedStats: Stats{Requests: 4, Retries: 3, Rejected: 1}},
+			},
+		},
+		{
+			name:     "requests and retries slide out of the window",
+			settings: Settings{Ratio: 0.5, MinRetriesPerSecond: 1, Window: 2 * time.Second},
+			steps: []step{
+				{requests: 4, retries: 4, expectedAllowed: 4, expectedStats: Stats{Requests: 4, Retries: 4}},
+				{advance: time.Second, requests: 2, expectedStats: Stats{Requests: 6, Retries: 4, Available: 1}},
+				{advance: time.Second, expectedStats: Stats{Requests: 2, Available: 3}},
+				{advance: 5 * time.Second, expectedStats: Stats{Available: 2}},
+			},
+		},
+	}
+	originalNow := now
+	defer func() { now = originalNow }()
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			current := time.Date(2023, time.April, 9, 10, 0, 0, 0, time.UTC)
+			now = func() time.Time { return current }
+			b := New(tc.settings)
+			for i, s := range tc.steps {
+				current = current.Add(s.advance)
+				for j := 0; j < s.requests; j++ {
+					b.RecordRequest()
+				}
+				var allowed int
+				for j := 0; j < s.retries; j++ {
+					if b.AllowRetry() {
+						allowed++
+					}
+				}
+				require.Equal(t, s.expectedAllowed, allowed, "step %d", i)
+				require.Equal(t, s.expectedStats, b.Stats(), "step %d", i)
+			}
+		})
+	}
+}
+
+func TestNewDefaults(t *testing.T) {
+	b := New(Settings{})
+	require.Equal(t, Settings{
+		Ratio:               DefaultRatio,
+		MinRetriesPerSecond: DefaultMinRetriesPerSecond,
+		Window:              DefaultWindow,
+	}, b.settings)
+	require.Len(t, b.buckets, 10)
+	require.Equal(t, 100, b.Stats().Available)
+}
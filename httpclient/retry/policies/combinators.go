@@ -0,0 +1,117 @@
+package policies
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	"github.com/hashicorp/go-retryablehttp"
+)
+
+// retryCounterKey is the context key under which
+// the per-call retry counter is stored.
+type retryCounterKey struct{}
+
+// retryCounter counts how many times each
+// limited policy decided to retry a call.
+type retryCounter struct {
+	mu     sync.Mutex
+	counts map[*int]int
+}
+
+// increment increments the counter for the given
+// limited policy and returns its new value.
+func (rc *retryCounter) increment(key *int) int {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.counts[key]++
+	return rc.counts[key]
+}
+
+// WithRetryCounter returns a copy of ctx carrying the per-call
+// state required by Limit. httpclient.Client attaches it to
+// every request it sends, so it is only needed when using
+// these policies with other clients.
+func WithRetryCounter(ctx context.Context) context.Context {
+	return context.WithValue(ctx, retryCounterKey{}, &retryCounter{
+		counts: make(map[*int]int),
+	})
+}
+
+// Any returns a policy that retries a request when any of the
+// given policies does. Policies are evaluated in order and the
+// evaluation stops at the first one that decides to retry.
+func Any(policies ...retryablehttp.CheckRetry) retryablehttp.CheckRetry {
+	return func(ctx context.Context, resp *http.Response, err error) (bool, error) {
+		var firstErr error
+		for _, policy := range policies {
+			retry, checkErr := policy(ctx, resp, err)
+			if retry {
+				return true, checkErr
+			}
+			if firstErr == nil {
+				firstErr = checkErr
+			}
+		}
+		return false, firstErr
+	}
+}
+
+// All returns a policy that retries a request only when all the
+// given policies do. Policies are evaluated in order and the
+// evaluation stops at the first one that decides not to retry.
+func All(policies ...retryablehttp.CheckRetry) retryablehttp.CheckRetry {
+	return func(ctx context.Context, resp *http.Response, err error) (bool, error) {
+		retry, checkErr := false, err
+		for _, policy := range policies {
+			retry, checkErr = policy(ctx, resp, err)
+			if !retry {
+				return false, checkErr
+			}
+		}
+		return retry, checkErr
+	}
+}
+
+// Not returns a policy that retries a failed request when the given
+// policy doesn't, and vice versa. A request is failed when it got an
+// error or an unsuccessful response. Successful requests and requests
+// whose context was canceled are never retried.
+func Not(policy retryablehttp.CheckRetry) retryablehttp.CheckRetry {
+	return func(ctx context.Context, resp *http.Response, err error) (bool, error) {
+		retry, checkErr := policy(ctx, resp, err)
+		if canceled(ctx, err) || !failed(resp, err) {
+			return false, checkErr
+		}
+		return !retry, checkErr
+	}
+}
+
+// failed reports whether a request got an error
+// or an unsuccessful response.
+func failed(resp *http.Response, err error) bool {
+	return err != nil || resp == nil || resp.StatusCode >= http.StatusBadRequest
+}
+
+// Limit returns a policy that retries a request as the given
+// policy does, but at most n times per call. Each Limit policy
+// keeps its own count, so it can be used to cap a class of errors,
+// e.g. Any(Limit(1, NetworkErrors), StatusCodes(503)) retries
+// network errors once and 503 responses up to the client's
+// maximum number of retries. Without the per-call state attached
+// by WithRetryCounter, requests are never retried, since the number
+// of retries can't be capped.
+func Limit(n int, policy retryablehttp.CheckRetry) retryablehttp.CheckRetry {
+	key := new(int)
+	return func(ctx context.Context, resp *http.Response, err error) (bool, error) {
+		retry, checkErr := policy(ctx, resp, err)
+		if !retry {
+			return false, checkErr
+		}
+		counter, ok := ctx.Value(retryCounterKey{}).(*retryCounter)
+		if !ok {
+			return false, checkErr
+		}
+		return counter.increment(key) <= n, checkErr
+	}
+}
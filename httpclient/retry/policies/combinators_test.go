@@ -0,0 +1,212 @@
+package policies
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"syscall"
+	"testing"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/stretchr/testify/require"
+)
+
+func alwaysRetry(ctx context.Context, resp *http.Response, err error) (bool, error) {
+	return true, err
+}
+
+func policyWithError(retry bool, err error) retryablehttp.CheckRetry {
+	return func(ctx context.Context, resp *http.Response, _ error) (bool, error) {
+		return retry, err
+	}
+}
+
+func TestAny(t *testing.T) {
+	testCases := []struct {
+		name           string
+		policies       []retryablehttp.CheckRetry
+		expectedOutput bool
+		expectedError  error
+	}{
+		{
+			name: "without policies",
+		},
+		{
+			name:     "none retries",
+			policies: []retryablehttp.CheckRetry{DoNotRetry, DoNotRetry},
+		},
+		{
+			name:           "one retries",
+			policies:       []retryablehttp.CheckRetry{DoNotRetry, alwaysRetry},
+			expectedOutput: true,
+		},
+		{
+			name: "none retries, with errors",
+			policies: []retryablehttp.CheckRetry{
+				policyWithError(false, errors.New("first error")),
+				policyWithError(false, errors.New("second error")),
+			},
+			expectedError: errors.New("first error"),
+		},
+		{
+			name: "one retries, with error",
+			policies: []retryablehttp.CheckRetry{
+				policyWithError(false, errors.New("first error")),
+				policyWithError(true, errors.New("second error")),
+			},
+			expectedOutput: true,
+			expectedError:  errors.New("second error"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			retry, err := Any(tc.policies...)(context.TODO(), new(http.Response), nil)
+			require.Equal(t, tc.expectedOutput, retry)
+			require.Equal(t, tc.expectedError, err)
+		})
+	}
+}
+
+func TestAll(t *testing.T) {
+	testCases := []struct {
+		name           string
+		policies       []retryablehttp.CheckRetry
+		expectedOutput bool
+		expectedError  error
+	}{
+		{
+			name: "without policies",
+		},
+		{
+			name:           "all retry",
+			policies:       []retryablehttp.CheckRetry{alwaysRetry, alwaysRetry},
+			expectedOutput: true,
+		},
+		{
+			name:     "one doesn't retry",
+			policies: []retryablehttp.CheckRetry{alwaysRetry, DoNotRetry},
+		},
+		{
+			name: "one doesn't retry, with error",
+			policies: []retryablehttp.CheckRetry{
+				alwaysRetry,
+				policyWithError(false, errors.New("random error")),
+				alwaysRetry,
+			},
+			expectedError: errors.New("random error"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			retry, err := All(tc.policies...)(context.TODO(), new(http.Response), nil)
+			require.Equal(t, tc.expectedOutput, retry)
+			require.Equal(t, tc.expectedError, err)
+		})
+	}
+}
+
+func TestNot(t *testing.T) {
+	testCases := []struct {
+		name           string
+		ctx            context.Context
+		policy         retryablehttp.CheckRetry
+		resp           *http.Response
+		err            error
+		expectedOutput bool
+	}{
+		{
+			name:           "policy doesn't retry",
+			ctx:            context.TODO(),
+			policy:         DoNotRetry,
+			resp:           &http.Response{StatusCode: http.StatusInternalServerError},
+			expectedOutput: true,
+		},
+		{
+			name:           "policy doesn't retry an error",
+			ctx:            context.TODO(),
+			policy:         DoNotRetry,
+			err:            netOpError(syscall.ECONNREFUSED),
+			expectedOutput: true,
+		},
+		{
+			name:   "policy retries",
+			ctx:    context.TODO(),
+			policy: alwaysRetry,
+			resp:   &http.Response{StatusCode: http.StatusInternalServerError},
+		},
+		{
+			name:   "successful response",
+			ctx:    context.TODO(),
+			policy: StatusCodes(http.StatusNotFound),
+			resp:   &http.Response{StatusCode: http.StatusOK},
+		},
+		{
+			name:   "with canceled context",
+			ctx:    canceledContext(),
+			policy: DoNotRetry,
+			resp:   &http.Response{StatusCode: http.StatusInternalServerError},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			retry, err := Not(tc.policy)(tc.ctx, tc.resp, tc.err)
+			require.Equal(t, tc.expectedOutput, retry)
+			require.Nil(t, err)
+		})
+	}
+}
+
+func TestLimit(t *testing.T) {
+	testCases := []struct {
+		name            string
+		ctx             context.Context
+		policy          retryablehttp.CheckRetry
+		expectedOutputs []bool
+	}{
+		{
+			name:            "policy doesn't retry",
+			ctx:             WithRetryCounter(context.TODO()),
+			policy:          DoNotRetry,
+			expectedOutputs: []bool{false, false, false},
+		},
+		{
+			name:            "policy retries",
+			ctx:             WithRetryCounter(context.TODO()),
+			policy:          alwaysRetry,
+			expectedOutputs: []bool{true, true, false},
+		},
+		{
+			name:            "without retry counter",
+			ctx:             context.TODO(),
+			policy:          alwaysRetry,
+			expectedOutputs: []bool{false, false, false},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			policy := Limit(2, tc.policy)
+			for _, expectedOutput := range tc.expectedOutputs {
+				retry, err := policy(tc.ctx, new(http.Response), nil)
+				require.Equal(t, expectedOutput, retry)
+				require.Nil(t, err)
+			}
+		})
+	}
+}
+
+func TestLimitKeepsCountPerPolicy(t *testing.T) {
+	ctx := WithRetryCounter(context.TODO())
+	policy := Any(Limit(1, NetworkErrors), Limit(2, StatusCodes(http.StatusServiceUnavailable)))
+	unavailable := &http.Response{StatusCode: http.StatusServiceUnavailable}
+	refused := netOpError(syscall.ECONNREFUSED)
+	retry, _ := policy(ctx, nil, refused)
+	require.True(t, retry)
+	retry, _ = policy(ctx, unavailable, nil)
+	require.True(t, retry)
+	retry, _ = policy(ctx, nil, refused)
+	require.False(t, retry)
+	retry, _ = policy(ctx, unavailable, nil)
+	require.True(t, retry)
+	retry, _ = policy(ctx, unavailable, nil)
+	require.False(t, retry)
+}
@@ -2,8 +2,13 @@ package policies
 
 import (
 	"context"
+	"errors"
+	"net"
 	"net/http"
 	"strings"
+	"syscall"
+
+	"github.com/hashicorp/go-retryablehttp"
 )
 
 // DoNotRetry policy does not retry a failed request.
@@ -18,3 +23,64 @@ func Eof(ctx context.Context, resp *http.Response, err error) (bool, error) {
 	}
 	return false, err
 }
+
+// StatusCodes returns a policy that retries a request when
+// the response has any of the given status codes,
+// e.g. StatusCodes(http.StatusTooManyRequests, http.StatusServiceUnavailable).
+func StatusCodes(codes ...int) retryablehttp.CheckRetry {
+	return func(ctx context.Context, resp *http.Response, err error) (bool, error) {
+		if canceled(ctx, err) || resp == nil {
+			return false, err
+		}
+		for _, code := range codes {
+			if resp.StatusCode == code {
+				return true, err
+			}
+		}
+		return false, err
+	}
+}
+
+// StatusClasses returns a policy that retries a request when
+// the response status code belongs to any of the given classes,
+// e.g. StatusClasses(5) retries on any 5xx response.
+func StatusClasses(classes ...int) retryablehttp.CheckRetry {
+	const classDivisor = 100
+	return func(ctx context.Context, resp *http.Response, err error) (bool, error) {
+		if canceled(ctx, err) || resp == nil {
+			return false, err
+		}
+		for _, class := range classes {
+			if resp.StatusCode/classDivisor == class {
+				return true, err
+			}
+		}
+		return false, err
+	}
+}
+
+// NetworkErrors policy retries a request in case of connection refused,
+// connection reset or timeout errors.
+func NetworkErrors(ctx context.Context, resp *http.Response, err error) (bool, error) {
+	if err == nil || canceled(ctx, err) {
+		return false, err
+	}
+	return IsNetworkError(err), err
+}
+
+// IsNetworkError reports whether the given error is a
+// connection refused, connection reset or timeout error.
+func IsNetworkError(err error) bool {
+	if errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// canceled reports whether the request context is done or
+// the given error is a context cancellation, in which case
+// a request must never be retried.
+func canceled(ctx context.Context, err error) bool {
+	return ctx.Err() != nil || errors.Is(err, context.Canceled)
+}
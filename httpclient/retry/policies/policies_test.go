@@ -3,7 +3,11 @@ package policies
 import (
 	"context"
 	"errors"
+	"net"
 	"net/http"
+	"net/url"
+	"os"
+	"syscall"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -58,3 +62,155 @@ func TestEof(t *testing.T) {
 		})
 	}
 }
+
+func TestStatusCodes(t *testing.T) {
+	testCases := []struct {
+		name           string
+		ctx            context.Context
+		resp           *http.Response
+		err            error
+		expectedOutput bool
+	}{
+		{
+			name: "without response",
+			ctx:  context.TODO(),
+			err:  errors.New("random error"),
+		},
+		{
+			name:           "with matching status code",
+			ctx:            context.TODO(),
+			resp:           &http.Response{StatusCode: http.StatusTooManyRequests},
+			expectedOutput: true,
+		},
+		{
+			name: "with different status code",
+			ctx:  context.TODO(),
+			resp: &http.Response{StatusCode: http.StatusInternalServerError},
+		},
+		{
+			name: "with canceled context",
+			ctx:  canceledContext(),
+			resp: &http.Response{StatusCode: http.StatusTooManyRequests},
+		},
+	}
+	policy := StatusCodes(http.StatusTooManyRequests, http.StatusServiceUnavailable)
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			retry, err := policy(tc.ctx, tc.resp, tc.err)
+			require.Equal(t, tc.expectedOutput, retry)
+			require.Equal(t, tc.err, err)
+		})
+	}
+}
+
+func TestStatusClasses(t *testing.T) {
+	testCases := []struct {
+		name           string
+		ctx            context.Context
+		resp           *http.Response
+		err            error
+		expectedOutput bool
+	}{
+		{
+			name: "without response",
+			ctx:  context.TODO(),
+			err:  errors.New("random error"),
+		},
+		{
+			name:           "with matching status class",
+			ctx:            context.TODO(),
+			resp:           &http.Response{StatusCode: http.StatusBadGateway},
+			expectedOutput: true,
+		},
+		{
+			name: "with different status class",
+			ctx:  context.TODO(),
+			resp: &http.Response{StatusCode: http.StatusBadRequest},
+		},
+		{
+			name: "with canceled context",
+			ctx:  canceledContext(),
+			resp: &http.Response{StatusCode: http.StatusBadGateway},
+		},
+	}
+	policy := StatusClasses(5)
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			retry, err := policy(tc.ctx, tc.resp, tc.err)
+			require.Equal(t, tc.expectedOutput, retry)
+			require.Equal(t, tc.err, err)
+		})
+	}
+}
+
+func TestNetworkErrors(t *testing.T) {
+	testCases := []struct {
+		name           string
+		ctx            context.Context
+		err            error
+		expectedOutput bool
+	}{
+		{
+			name: "without provided error",
+			ctx:  context.TODO(),
+		},
+		{
+			name:           "with connection refused error",
+			ctx:            context.TODO(),
+			err:            netOpError(syscall.ECONNREFUSED),
+			expectedOutput: true,
+		},
+		{
+			name:           "with connection reset error",
+			ctx:            context.TODO(),
+			err:            netOpError(syscall.ECONNRESET),
+			expectedOutput: true,
+		},
+		{
+			name:           "with timeout error",
+			ctx:            context.TODO(),
+			err:            &url.Error{Op: "Get", URL: "url", Err: os.ErrDeadlineExceeded},
+			expectedOutput: true,
+		},
+		{
+			name: "with different error",
+			ctx:  context.TODO(),
+			err:  errors.New("random error"),
+		},
+		{
+			name: "with context canceled error",
+			ctx:  context.TODO(),
+			err:  &url.Error{Op: "Get", URL: "url", Err: context.Canceled},
+		},
+		{
+			name: "with canceled context",
+			ctx:  canceledContext(),
+			err:  netOpError(syscall.ECONNREFUSED),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			retry, err := NetworkErrors(tc.ctx, nil, tc.err)
+			require.Equal(t, tc.expectedOutput, retry)
+			require.Equal(t, tc.err, err)
+		})
+	}
+}
+
+func canceledContext() context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	return ctx
+}
+
+func netOpError(errno syscall.Errno) error {
+	return &url.Error{
+		Op:  "Get",
+		URL: "url",
+		Err: &net.OpError{
+			Op:  "dial",
+			Net: "tcp",
+			Err: os.NewSyscallError("connect", errno),
+		},
+	}
+}
@@ -0,0 +1,124 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/pkg/errors"
+)
+
+// ErrRetryAfterExceedsDeadline is returned when the wait requested
+// by the server would outlast the request context deadline.
+var ErrRetryAfterExceedsDeadline = errors.New("requested retry wait exceeds request deadline")
+
+// defaultRetryAfterMaxWait is the longest wait asked by the server
+// that is honored when no other limit is configured.
+const defaultRetryAfterMaxWait = time.Minute
+
+// For ease of unit testing.
+// Declaring these functions as global variables
+// makes it easy to mock them.
+var (
+	now = time.Now
+)
+
+// retryAfter returns how long the server asked the client to wait
+// before retrying, through either the Retry-After header, in seconds
+// or as an HTTP date, or the X-RateLimit-Reset header, in seconds or
+// as a Unix timestamp. Only 429 and 503 responses are considered.
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	if resp == nil {
+		return 0, false
+	}
+	if resp.StatusCode != http.StatusTooManyRequests &&
+		resp.StatusCode != http.StatusServiceUnavailable {
+		return 0, false
+	}
+	if wait, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+		return wait, true
+	}
+	return parseRateLimitReset(resp.Header.Get("X-RateLimit-Reset"))
+}
+
+// parseRetryAfter parses a Retry-After header value.
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return nonNegative(time.Duration(seconds) * time.Second), true
+	}
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+	return nonNegative(date.Sub(now())), true
+}
+
+// parseRateLimitReset parses a X-RateLimit-Reset header value.
+// Values past 2001-09-09 are taken as Unix timestamps,
+// smaller ones as a number of seconds.
+func parseRateLimitReset(value string) (time.Duration, bool) {
+	const minUnixTimestamp = 1_000_000_000
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	if seconds < minUnixTimestamp {
+		return nonNegative(time.Duration(seconds) * time.Second), true
+	}
+	return nonNegative(time.Unix(seconds, 0).Sub(now())), true
+}
+
+// nonNegative returns zero for negative durations.
+func nonNegative(d time.Duration) time.Duration {
+	if d < 0 {
+		return 0
+	}
+	return d
+}
+
+// boundedRetryAfter returns how long the server asked the client
+// to wait, bounded by maxWait when it's greater than zero.
+func boundedRetryAfter(resp *http.Response, maxWait time.Duration) (time.Duration, bool) {
+	wait, ok := retryAfter(resp)
+	if ok && maxWait > 0 && wait > maxWait {
+		return maxWait, true
+	}
+	return wait, ok
+}
+
+// retryAfterBackoff returns a backoff that waits as long as the
+// server asked, bounded by maxWait, and falls back to the given
+// backoff otherwise.
+func retryAfterBackoff(backoff retryablehttp.Backoff, maxWait time.Duration) retryablehttp.Backoff {
+	return func(min, max time.Duration, attemptNum int, resp *http.Response) time.Duration {
+		if wait, ok := boundedRetryAfter(resp, maxWait); ok {
+			return wait
+		}
+		return backoff(min, max, attemptNum, resp)
+	}
+}
+
+// retryAfterCheckRetry returns a policy that gives up early, instead
+// of retrying as the given policy would, when the wait asked by
+// the server would outlast the request context deadline.
+func retryAfterCheckRetry(checkRetry retryablehttp.CheckRetry, maxWait time.Duration) retryablehttp.CheckRetry {
+	return func(ctx context.Context, resp *http.Response, err error) (bool, error) {
+		retry, checkErr := checkRetry(ctx, resp, err)
+		if !retry {
+			return retry, checkErr
+		}
+		wait, ok := boundedRetryAfter(resp, maxWait)
+		if !ok {
+			return retry, checkErr
+		}
+		if deadline, hasDeadline := ctx.Deadline(); hasDeadline && now().Add(wait).After(deadline) {
+			return false, ErrRetryAfterExceedsDeadline
+		}
+		return retry, checkErr
+	}
+}
@@ -0,0 +1,222 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tiagomelo/go-retryable-httpclient/httpclient/retry/policies"
+)
+
+func TestRetryAfter(t *testing.T) {
+	fixedNow := time.Date(2023, time.April, 9, 10, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		name         string
+		resp         *http.Response
+		expectedWait time.Duration
+		expectedOk   bool
+	}{
+		{
+			name: "without response",
+		},
+		{
+			name: "with status code that isn't 429 nor 503",
+			resp: &http.Response{
+				StatusCode: http.StatusInternalServerError,
+				Header:     http.Header{"Retry-After": {"3"}},
+			},
+		},
+		{
+			name: "without headers",
+			resp: &http.Response{StatusCode: http.StatusTooManyRequests},
+		},
+		{
+			name: "with Retry-After in seconds",
+			resp: &http.Response{
+				StatusCode: http.StatusTooManyRequests,
+				Header:     http.Header{"Retry-After": {"3"}},
+			},
+			expectedWait: 3 * time.Second,
+			expectedOk:   true,
+		},
+		{
+			name: "with Retry-After as HTTP date",
+			resp: &http.Response{
+				StatusCode: http.StatusServiceUnavailable,
+				Header:     http.Header{"Retry-After": {"Sun, 09 Apr 2023 10:00:05 GMT"}},
+			},
+			expectedWait: 5 * time.Second,
+			expectedOk:   true,
+		},
+		{
+			name: "with Retry-After as past HTTP date",
+			resp: &http.Response{
+				StatusCode: http.StatusServiceUnavailable,
+				Header:     http.Header{"Retry-After": {"Sun, 09 Apr 2023 09:59:00 GMT"}},
+			},
+			expectedOk: true,
+		},
+		{
+			name: "with invalid Retry-After",
+			resp: &http.Response{
+				StatusCode: http.StatusServiceUnavailable,
+				Header:     http.Header{"Retry-After": {"soon"}},
+			},
+		},
+		{
+			name: "with X-RateLimit-Reset in seconds",
+			resp: &http.Response{
+				StatusCode: http.StatusTooManyRequests,
+				Header:     http.Header{"X-Ratelimit-Reset": {"7"}},
+			},
+			expectedWait: 7 * time.Second,
+			expectedOk:   true,
+		},
+		{
+			name: "with X-RateLimit-Reset as Unix timestamp",
+			resp: &http.Response{
+				StatusCode: http.StatusTooManyRequests,
+				Header:     http.Header{"X-Ratelimit-Reset": {"1681034410"}},
+			},
+			expectedWait: 10 * time.Second,
+			expectedOk:   true,
+		},
+		{
+			name: "Retry-After takes precedence over X-RateLimit-Reset",
+			resp: &http.Response{
+				StatusCode: http.StatusTooManyRequests,
+				Header: http.Header{
+					"Retry-After":       {"2"},
+					"X-Ratelimit-Reset": {"7"},
+				},
+			},
+			expectedWait: 2 * time.Second,
+			expectedOk:   true,
+		},
+	}
+	originalNow := now
+	defer func() { now = originalNow }()
+	now = func() time.Time { return fixedNow }
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			wait, ok := retryAfter(tc.resp)
+			require.Equal(t, tc.expectedOk, ok)
+			require.Equal(t, tc.expectedWait, wait)
+		})
+	}
+}
+
+func TestWithRetryAfter(t *testing.T) {
+	testCases := []struct {
+		name             string
+		retryAfter       string
+		timeout          time.Duration
+		options          []Option
+		expectedAttempts int
+		expectedError    error
+	}{
+		{
+			name:             "waits as asked by the server",
+			retryAfter:       "0",
+			timeout:          time.Minute,
+			options:          []Option{WithRetryAfter(0)},
+			expectedAttempts: 2,
+		},
+		{
+			name:             "wait is bounded by the provided max wait",
+			retryAfter:       "3600",
+			timeout:          time.Minute,
+			options:          []Option{WithRetryAfter(time.Millisecond)},
+			expectedAttempts: 2,
+		},
+		{
+			name:             "wait is bounded by the retry max wait",
+			retryAfter:       "3600",
+			timeout:          time.Minute,
+			options:          []Option{WithRetryAfter(0), WithRetryWaitMax(time.Millisecond)},
+			expectedAttempts: 2,
+		},
+		{
+			name:             "gives up when wait exceeds the request deadline",
+			retryAfter:       "3600",
+			timeout:          time.Second,
+			options:          []Option{WithRetryAfter(0)},
+			expectedAttempts: 1,
+			expectedError:    ErrRetryAfterExceedsDeadline,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var attempts int
+			svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				attempts++
+				if attempts == 1 {
+					w.Header().Set("Retry-After", tc.retryAfter)
+					w.WriteHeader(http.StatusTooManyRequests)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer svr.Close()
+			options := append([]Option{
+				WithMaxRetries(1),
+				WithCheckRetryPolicy(policies.StatusCodes(http.StatusTooManyRequests)),
+			}, tc.options...)
+			client := New(options...)
+			ctx, cancel := context.WithTimeout(context.Background(), tc.timeout)
+			defer cancel()
+			req, err := NewRequest(ctx, http.MethodGet, svr.URL)
+			require.NoError(t, err)
+			start := time.Now()
+			_, err = client.SendRequest(req)
+			require.Less(t, time.Since(start), tc.timeout)
+			require.Equal(t, tc.expectedAttempts, attempts)
+			if err != nil {
+				checkIfErrorIsExpected(t, err, tc.expectedError)
+				require.True(t, strings.Contains(err.Error(), tc.expectedError.Error()))
+			} else {
+				checkIfErrorIsNotExpected(t, err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestRetryAfterWaitLimit(t *testing.T) {
+	testCases := []struct {
+		name          string
+		options       []Option
+		expectedLimit time.Duration
+	}{
+		{
+			name:          "with max wait",
+			options:       []Option{WithRetryWaitMax(time.Second), WithRetryAfter(2 * time.Second)},
+			expectedLimit: 2 * time.Second,
+		},
+		{
+			name:          "with retry wait max",
+			options:       []Option{WithRetryWaitMax(time.Second), WithRetryAfter(0)},
+			expectedLimit: time.Second,
+		},
+		{
+			name:          "without limits",
+			options:       []Option{WithRetryAfter(0)},
+			expectedLimit: defaultRetryAfterMaxWait,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := New(tc.options...)
+			require.Equal(t, tc.expectedLimit, client.retryAfterWaitLimit())
+			resp := &http.Response{
+				StatusCode: http.StatusServiceUnavailable,
+				Header:     http.Header{"Retry-After": {"86400"}},
+			}
+			wait := client.retryableHttpClient.Backoff(0, 0, 0, resp)
+			require.Equal(t, tc.expectedLimit, wait)
+		})
+	}
+}
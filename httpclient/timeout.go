@@ -0,0 +1,101 @@
+package httpclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/pkg/errors"
+	"github.com/tiagomelo/go-retryable-httpclient/httpclient/retry/budget"
+)
+
+// ErrAttemptExceedsDeadline is returned when the next attempt,
+// along with the wait before it, can't finish before the
+// request deadline.
+var ErrAttemptExceedsDeadline = errors.New("next attempt cannot finish before request deadline")
+
+// attemptBudget keeps the retry loop of a single call from starting
+// attempts that can't finish within the time left before its deadline.
+// To know how long the retry loop will wait before the next attempt,
+// the wait is computed when deciding whether to retry and then
+// handed over to the retry loop as is.
+type attemptBudget struct {
+	attemptTimeout time.Duration
+	maxRetries     int
+	nextBackoff    retryablehttp.Backoff
+	retryBudget    *budget.Budget
+	attemptNum     int
+	wait           time.Duration
+}
+
+// checkRetry wraps the given check retry policy, giving up when
+// the next attempt can't finish before the request deadline
+// or when the client retry budget is exhausted.
+func (b *attemptBudget) checkRetry(checkRetry retryablehttp.CheckRetry,
+	min, max time.Duration) retryablehttp.CheckRetry {
+	return func(ctx context.Context, resp *http.Response, err error) (bool, error) {
+		retry, checkErr := checkRetry(ctx, resp, err)
+		attemptNum := b.attemptNum
+		b.attemptNum++
+		if !retry || attemptNum >= b.maxRetries {
+			return retry, checkErr
+		}
+		b.wait = b.nextBackoff(min, max, attemptNum, resp)
+		if err := b.refuseRetry(ctx); err != nil {
+			return false, err
+		}
+		return retry, checkErr
+	}
+}
+
+// refuseRetry returns the reason why the next attempt must not
+// be started, if any. The retry budget is withdrawn from last,
+// only when the attempt is started.
+func (b *attemptBudget) refuseRetry(ctx context.Context) error {
+	deadline, hasDeadline := ctx.Deadline()
+	if hasDeadline && now().Add(b.wait+b.attemptTimeout).After(deadline) {
+		return ErrAttemptExceedsDeadline
+	}
+	if b.retryBudget != nil && !b.retryBudget.AllowRetry() {
+		return budget.ErrExhausted
+	}
+	return nil
+}
+
+// backoff returns the wait computed when deciding to retry.
+func (b *attemptBudget) backoff(min, max time.Duration, attemptNum int, resp *http.Response) time.Duration {
+	return b.wait
+}
+
+// cancelOnClose cancels a context once the body is closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+// Close closes the body and cancels the context.
+func (b *cancelOnClose) Close() error {
+	defer b.cancel()
+	return b.ReadCloser.Close()
+}
+
+// withTotalTimeout bounds the whole call, every attempt and every
+// wait between them, to the given timeout. The returned function
+// must be called with the response, so that the timeout is
+// released once the response body is closed.
+func withTotalTimeout(req *retryablehttp.Request, timeout time.Duration) (*retryablehttp.Request,
+	func(resp *http.Response)) {
+	if timeout <= 0 {
+		return req, func(resp *http.Response) {}
+	}
+	ctx, cancel := context.WithTimeout(req.Context(), timeout)
+	return req.WithContext(ctx), func(resp *http.Response) {
+		if resp == nil || resp.Body == nil {
+			cancel()
+			return
+		}
+		resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	}
+}
@@ -0,0 +1,127 @@
+package httpclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tiagomelo/go-retryable-httpclient/httpclient/retry/backoff"
+	"github.com/tiagomelo/go-retryable-httpclient/httpclient/retry/policies"
+)
+
+func TestWithAttemptTimeout(t *testing.T) {
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer svr.Close()
+	client := New(
+		WithHttpClient(&http.Client{}),
+		WithAttemptTimeout(10*time.Millisecond),
+	)
+	req, err := NewRequest(context.TODO(), http.MethodGet, svr.URL)
+	require.NoError(t, err)
+	start := time.Now()
+	_, err = client.SendRequest(req)
+	require.NotNil(t, err)
+	require.Less(t, time.Since(start), time.Second)
+}
+
+func TestTotalTimeout(t *testing.T) {
+	testCases := []struct {
+		name             string
+		options          []Option
+		requestOptions   []RequestOption
+		expectedAttempts int
+		expectedError    error
+	}{
+		{
+			name: "retries until the total timeout",
+			options: []Option{
+				WithRetryWaitMin(20 * time.Millisecond),
+				WithTotalTimeout(100 * time.Millisecond),
+			},
+		},
+		{
+			name: "total timeout overridden per request",
+			options: []Option{
+				WithRetryWaitMin(20 * time.Millisecond),
+				WithTotalTimeout(time.Minute),
+			},
+			requestOptions: []RequestOption{WithRequestTotalTimeout(100 * time.Millisecond)},
+		},
+		{
+			name: "refuses an attempt that can't finish before the deadline",
+			options: []Option{
+				WithRetryWaitMin(300 * time.Millisecond),
+				WithAttemptTimeout(500 * time.Millisecond),
+				WithTotalTimeout(700 * time.Millisecond),
+			},
+			expectedAttempts: 1,
+			expectedError:    ErrAttemptExceedsDeadline,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				mu       sync.Mutex
+				attempts int
+			)
+			svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				defer mu.Unlock()
+				attempts++
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}))
+			defer svr.Close()
+			options := append([]Option{
+				WithMaxRetries(100),
+				WithBackoff(backoff.Constant),
+				WithCheckRetryPolicy(policies.StatusCodes(http.StatusServiceUnavailable)),
+			}, tc.options...)
+			client := New(options...)
+			req, err := NewRequest(context.TODO(), http.MethodGet, svr.URL)
+			require.NoError(t, err)
+			start := time.Now()
+			_, err = client.SendRequest(req, tc.requestOptions...)
+			require.NotNil(t, err)
+			require.Less(t, time.Since(start), 500*time.Millisecond)
+			if tc.expectedAttempts > 0 {
+				require.Equal(t, tc.expectedAttempts, attempts)
+			} else {
+				require.Greater(t, attempts, 1)
+				require.Less(t, attempts, 100)
+			}
+			if tc.expectedError != nil {
+				require.True(t, strings.Contains(err.Error(), tc.expectedError.Error()))
+			}
+		})
+	}
+}
+
+func TestTotalTimeoutKeepsResponseBodyReadable(t *testing.T) {
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.(http.Flusher).Flush()
+		time.Sleep(20 * time.Millisecond)
+		_, err := io.WriteString(w, "some body")
+		require.NoError(t, err)
+	}))
+	defer svr.Close()
+	client := New(WithTotalTimeout(time.Minute))
+	req, err := NewRequest(context.TODO(), http.MethodGet, svr.URL)
+	require.NoError(t, err)
+	resp, err := client.SendRequest(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, "some body", string(b))
+}
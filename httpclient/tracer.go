@@ -0,0 +1,65 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+)
+
+// Tracer traces calls and their attempts, e.g. with OpenTelemetry
+// as the tracing subpackage does. It can be used with WithTracer.
+//
+// Both methods are given the url of the request as it should be
+// exported, with its password and the values of the query
+// parameters given to WithRedactedQueryParams masked.
+type Tracer interface {
+	// StartCall is called when a call of the given request starts.
+	// It returns the context of the call, along with a function
+	// called with the outcome of the call once it ends.
+	StartCall(ctx context.Context, req *http.Request, url string) (context.Context, func(resp *http.Response, err error))
+	// StartAttempt is called before every attempt of a call is
+	// sent, attempt being its number, starting at 1. The given
+	// request is a copy whose headers may be modified, e.g. to
+	// propagate the trace context. It returns the context of the
+	// attempt, along with a function called with its outcome.
+	StartAttempt(ctx context.Context, req *http.Request, url string, attempt int) (context.Context, func(resp *http.Response, err error))
+}
+
+// tracedTransport is an http.RoundTripper that
+// traces every attempt of a single call.
+type tracedTransport struct {
+	next     http.RoundTripper
+	tracer   Tracer
+	redactor *redactor
+	attempts int
+}
+
+// RoundTrip traces the attempt and sends the request.
+func (t *tracedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.attempts++
+	// A RoundTripper must not modify the request,
+	// so the trace context goes into a copy of it.
+	req = req.Clone(req.Context())
+	ctx, end := t.tracer.StartAttempt(req.Context(), req, t.redactor.url(req.URL).String(), t.attempts)
+	resp, err := t.next.RoundTrip(req.WithContext(ctx))
+	end(resp, err)
+	return resp, err
+}
+
+// tracedClient returns a copy of the given http client
+// whose transport traces every attempt of a single call.
+func (c *Client) tracedClient(httpClient *http.Client) *http.Client {
+	if c.tracer == nil {
+		return httpClient
+	}
+	next := httpClient.Transport
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	hc := *httpClient
+	hc.Transport = &tracedTransport{
+		next:     next,
+		tracer:   c.tracer,
+		redactor: c.redactor,
+	}
+	return &hc
+}
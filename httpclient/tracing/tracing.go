@@ -0,0 +1,132 @@
+// Package tracing provides an OpenTelemetry tracer, which starts an
+// internal span for every call and a client span for every attempt of
+// it, propagating the trace context into the outgoing requests.
+// It can be used with httpclient.WithTracer.
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+
+	"github.com/tiagomelo/go-retryable-httpclient/httpclient"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// InstrumentationName is the name of the tracer spans are started with.
+const InstrumentationName = "github.com/tiagomelo/go-retryable-httpclient/httpclient/tracing"
+
+// Settings configures a Tracer.
+type Settings struct {
+	// TracerProvider provides the tracer spans are started with.
+	// Defaults to the global tracer provider.
+	TracerProvider trace.TracerProvider
+	// Propagator injects the trace context into the outgoing
+	// requests. Defaults to the W3C trace context propagator.
+	Propagator propagation.TextMapPropagator
+}
+
+// Tracer traces calls and their attempts with OpenTelemetry.
+// It implements httpclient.Tracer.
+type Tracer struct {
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+}
+
+// New returns a new Tracer.
+func New(settings Settings) *Tracer {
+	if settings.TracerProvider == nil {
+		settings.TracerProvider = otel.GetTracerProvider()
+	}
+	if settings.Propagator == nil {
+		settings.Propagator = propagation.TraceContext{}
+	}
+	return &Tracer{
+		tracer:     settings.TracerProvider.Tracer(InstrumentationName),
+		propagator: settings.Propagator,
+	}
+}
+
+// StartCall starts the span of a call. It is an internal span, since
+// each physical request gets a client span of its own, as a child of it.
+func (t *Tracer) StartCall(ctx context.Context, req *http.Request, url string) (context.Context, func(resp *http.Response, err error)) {
+	ctx, span := t.tracer.Start(ctx, req.Method,
+		trace.WithSpanKind(trace.SpanKindInternal),
+		trace.WithAttributes(requestAttributes(req, url)...),
+	)
+	return ctx, func(resp *http.Response, err error) {
+		end(span, resp, err)
+	}
+}
+
+// StartAttempt starts the span of an attempt, as a child of the
+// span of its call, and injects its context into the request headers.
+func (t *Tracer) StartAttempt(ctx context.Context, req *http.Request, url string, attempt int) (context.Context, func(resp *http.Response, err error)) {
+	attrs := requestAttributes(req, url)
+	if attempt > 1 {
+		attrs = append(attrs, semconv.HTTPRequestResendCount(attempt-1))
+	}
+	ctx, span := t.tracer.Start(ctx, req.Method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+	t.propagator.Inject(ctx, propagation.HeaderCarrier(req.Header))
+	return ctx, func(resp *http.Response, err error) {
+		end(span, resp, err)
+	}
+}
+
+// requestAttributes returns the attributes describing the
+// given request, whose url is given already redacted.
+func requestAttributes(req *http.Request, url string) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{
+		semconv.HTTPRequestMethodKey.String(req.Method),
+		semconv.URLFull(url),
+	}
+	host, port, err := net.SplitHostPort(req.URL.Host)
+	if err != nil {
+		host = req.URL.Hostname()
+	}
+	attrs = append(attrs, semconv.ServerAddress(host))
+	if p, err := strconv.Atoi(port); err == nil {
+		attrs = append(attrs, semconv.ServerPort(p))
+	}
+	return attrs
+}
+
+// end ends the given span, recording the outcome of the call or
+// attempt. Errors and unsuccessful responses set the span status to
+// error. When the response was discarded, e.g. when giving up
+// retrying, the status code is taken from the error.
+func end(span trace.Span, resp *http.Response, err error) {
+	statusCode := 0
+	if resp != nil {
+		statusCode = resp.StatusCode
+	} else {
+		var httpErr *httpclient.HttpError
+		if errors.As(err, &httpErr) {
+			statusCode = httpErr.StatusCode
+		}
+	}
+	if statusCode != 0 {
+		span.SetAttributes(semconv.HTTPResponseStatusCode(statusCode))
+	}
+	switch {
+	case err != nil:
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		span.SetAttributes(semconv.ErrorTypeKey.String(fmt.Sprintf("%T", err)))
+	case statusCode >= http.StatusBadRequest:
+		span.SetStatus(codes.Error, http.StatusText(statusCode))
+		span.SetAttributes(semconv.ErrorTypeKey.String(strconv.Itoa(statusCode)))
+	}
+	span.End()
+}
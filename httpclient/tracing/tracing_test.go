@@ -0,0 +1,167 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tiagomelo/go-retryable-httpclient/httpclient"
+	"github.com/tiagomelo/go-retryable-httpclient/httpclient/retry/policies"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// attributes returns the attributes of the given span by key.
+func attributes(span tracetest.SpanStub) map[attribute.Key]attribute.Value {
+	attrs := make(map[attribute.Key]attribute.Value)
+	for _, attr := range span.Attributes {
+		attrs[attr.Key] = attr.Value
+	}
+	return attrs
+}
+
+func TestTracer(t *testing.T) {
+	testCases := []struct {
+		name               string
+		statuses           []int
+		expectedStatuses   []int64
+		expectedCallStatus int64
+		expectedCallCode   codes.Code
+	}{
+		{
+			name:               "retried until success",
+			statuses:           []int{http.StatusServiceUnavailable, http.StatusOK},
+			expectedStatuses:   []int64{http.StatusServiceUnavailable, http.StatusOK},
+			expectedCallStatus: http.StatusOK,
+			expectedCallCode:   codes.Unset,
+		},
+		{
+			name:               "retries exhausted",
+			statuses:           []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable},
+			expectedStatuses:   []int64{http.StatusServiceUnavailable, http.StatusServiceUnavailable},
+			expectedCallStatus: http.StatusServiceUnavailable,
+			expectedCallCode:   codes.Error,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				mu           sync.Mutex
+				statuses     = tc.statuses
+				traceparents []string
+			)
+			svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				defer mu.Unlock()
+				traceparents = append(traceparents, r.Header.Get("traceparent"))
+				w.WriteHeader(statuses[0])
+				statuses = statuses[1:]
+			}))
+			defer svr.Close()
+			exporter := tracetest.NewInMemoryExporter()
+			provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+			client := httpclient.New(
+				httpclient.WithMaxRetries(1),
+				httpclient.WithRetryWaitMin(time.Millisecond),
+				httpclient.WithRetryWaitMax(time.Millisecond),
+				httpclient.WithCheckRetryPolicy(policies.StatusCodes(http.StatusServiceUnavailable)),
+				httpclient.WithTracer(New(Settings{TracerProvider: provider})),
+			)
+			ctx, parent := provider.Tracer("test").Start(context.TODO(), "parent")
+			req, err := httpclient.NewRequest(ctx, http.MethodGet, svr.URL)
+			require.NoError(t, err)
+			resp, err := client.SendRequest(req)
+			if err == nil {
+				require.NoError(t, resp.Body.Close())
+			}
+			parent.End()
+
+			spans := exporter.GetSpans()
+			require.Len(t, spans, 4)
+			attempts, call := spans[:2], spans[2]
+			require.Equal(t, http.MethodGet, call.Name)
+			require.Equal(t, trace.SpanKindInternal, call.SpanKind)
+			require.Equal(t, parent.SpanContext().SpanID(), call.Parent.SpanID())
+			require.Equal(t, tc.expectedCallCode, call.Status.Code)
+			callAttrs := attributes(call)
+			require.Equal(t, http.MethodGet, callAttrs["http.request.method"].AsString())
+			require.Equal(t, "127.0.0.1", callAttrs["server.address"].AsString())
+			require.Equal(t, tc.expectedCallStatus, callAttrs["http.response.status_code"].AsInt64())
+			require.NotContains(t, callAttrs, attribute.Key("http.request.resend_count"))
+			for i, attempt := range attempts {
+				require.Equal(t, http.MethodGet, attempt.Name)
+				require.Equal(t, trace.SpanKindClient, attempt.SpanKind)
+				require.Equal(t, call.SpanContext.SpanID(), attempt.Parent.SpanID())
+				require.Equal(t, call.SpanContext.TraceID(), attempt.SpanContext.TraceID())
+				attrs := attributes(attempt)
+				require.Equal(t, tc.expectedStatuses[i], attrs["http.response.status_code"].AsInt64())
+				require.Equal(t, "127.0.0.1", attrs["server.address"].AsString())
+				if i == 0 {
+					require.NotContains(t, attrs, attribute.Key("http.request.resend_count"))
+				} else {
+					require.Equal(t, int64(i), attrs["http.request.resend_count"].AsInt64())
+				}
+				if tc.expectedStatuses[i] >= http.StatusBadRequest {
+					require.Equal(t, codes.Error, attempt.Status.Code)
+				} else {
+					require.Equal(t, codes.Unset, attempt.Status.Code)
+				}
+				expectedTraceparent := fmt.Sprintf("00-%s-%s-01",
+					attempt.SpanContext.TraceID(), attempt.SpanContext.SpanID())
+				require.Equal(t, expectedTraceparent, traceparents[i])
+			}
+		})
+	}
+}
+
+func TestTracerRecordsErrors(t *testing.T) {
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	svr.Close()
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	client := httpclient.New(httpclient.WithTracer(New(Settings{TracerProvider: provider})))
+	req, err := httpclient.NewRequest(context.TODO(), http.MethodPost, svr.URL)
+	require.NoError(t, err)
+	_, err = client.SendRequest(req)
+	require.Error(t, err)
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+	for _, span := range spans {
+		require.Equal(t, codes.Error, span.Status.Code)
+		require.Len(t, span.Events, 1)
+		require.Equal(t, "exception", span.Events[0].Name)
+		require.NotContains(t, attributes(span), attribute.Key("http.response.status_code"))
+		require.Contains(t, attributes(span), attribute.Key("error.type"))
+	}
+	require.False(t, spans[1].Parent.IsValid())
+	require.Equal(t, spans[1].SpanContext.SpanID(), spans[0].Parent.SpanID())
+}
+
+func TestTracerRedactsUrls(t *testing.T) {
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer svr.Close()
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	client := httpclient.New(
+		httpclient.WithRedactedQueryParams("token"),
+		httpclient.WithTracer(New(Settings{TracerProvider: provider})),
+	)
+	req, err := httpclient.NewRequest(context.TODO(), http.MethodGet, svr.URL+"?token=secret")
+	require.NoError(t, err)
+	resp, err := client.SendRequest(req)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+	for _, span := range spans {
+		require.Equal(t, svr.URL+"?token=%5BREDACTED%5D", attributes(span)["url.full"].AsString())
+	}
+}
@@ -0,0 +1,53 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+)
+
+// Do sends an HTTP request and decodes the response body into
+// a value of type T, which is returned along with the response.
+// The given options override the Client settings for this request only.
+func Do[T any](client *Client, req *http.Request, options ...RequestOption) (T, *http.Response, error) {
+	var v T
+	resp, err := client.SendRequestAndUnmarshallJsonResponse(req, &v, options...)
+	return v, resp, err
+}
+
+// Get sends a GET request to the given url and decodes the
+// response body into a value of type T.
+func Get[T any](ctx context.Context, client *Client, url string,
+	options ...RequestOption) (T, *http.Response, error) {
+	req, err := NewRequest(ctx, http.MethodGet, url)
+	if err != nil {
+		var zero T
+		return zero, nil, err
+	}
+	return Do[T](client, req, options...)
+}
+
+// Post sends a POST request to the given url with the given payload
+// json encoded, and decodes the response body into a value of type Resp.
+func Post[Req, Resp any](ctx context.Context, client *Client, url string, payload Req,
+	options ...RequestOption) (Resp, *http.Response, error) {
+	return sendJson[Req, Resp](ctx, client, http.MethodPost, url, payload, options)
+}
+
+// Put sends a PUT request to the given url with the given payload
+// json encoded, and decodes the response body into a value of type Resp.
+func Put[Req, Resp any](ctx context.Context, client *Client, url string, payload Req,
+	options ...RequestOption) (Resp, *http.Response, error) {
+	return sendJson[Req, Resp](ctx, client, http.MethodPut, url, payload, options)
+}
+
+// sendJson sends a request with the given payload json encoded,
+// and decodes the response body into a value of type Resp.
+func sendJson[Req, Resp any](ctx context.Context, client *Client, method, url string, payload Req,
+	options []RequestOption) (Resp, *http.Response, error) {
+	req, err := NewJsonRequest(ctx, method, url, payload)
+	if err != nil {
+		var zero Resp
+		return zero, nil, err
+	}
+	return Do[Resp](client, req, options...)
+}
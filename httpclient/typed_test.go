@@ -0,0 +1,102 @@
+package httpclient
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tiagomelo/go-retryable-httpclient/httpclient/codec"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// echoHandler responds with the method of the request
+// and the key of its json body, if any.
+func echoHandler(w http.ResponseWriter, r *http.Request) {
+	var payload dummyType
+	body, _ := io.ReadAll(r.Body)
+	if len(body) > 0 {
+		json.Unmarshal(body, &payload)
+	}
+	json.NewEncoder(w).Encode(dummyType{Key: r.Method + " " + payload.Key})
+}
+
+func TestTypedHelpers(t *testing.T) {
+	svr := httptest.NewServer(http.HandlerFunc(echoHandler))
+	defer svr.Close()
+	client := New()
+	testCases := []struct {
+		name        string
+		send        func() (dummyType, *http.Response, error)
+		expectedKey string
+	}{
+		{
+			name: "Get",
+			send: func() (dummyType, *http.Response, error) {
+				return Get[dummyType](context.TODO(), client, svr.URL)
+			},
+			expectedKey: "GET ",
+		},
+		{
+			name: "Post",
+			send: func() (dummyType, *http.Response, error) {
+				return Post[dummyType, dummyType](context.TODO(), client, svr.URL, dummyType{Key: "value"})
+			},
+			expectedKey: "POST value",
+		},
+		{
+			name: "Put",
+			send: func() (dummyType, *http.Response, error) {
+				return Put[dummyType, dummyType](context.TODO(), client, svr.URL, dummyType{Key: "value"})
+			},
+			expectedKey: "PUT value",
+		},
+		{
+			name: "Do",
+			send: func() (dummyType, *http.Response, error) {
+				req, err := NewRequest(context.TODO(), http.MethodDelete, svr.URL)
+				require.NoError(t, err)
+				return Do[dummyType](client, req)
+			},
+			expectedKey: "DELETE ",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			v, resp, err := tc.send()
+			require.NoError(t, err)
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+			require.Equal(t, tc.expectedKey, v.Key)
+		})
+	}
+}
+
+func TestTypedHelpersWithProtobuf(t *testing.T) {
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", codec.Protobuf.ContentType())
+		codec.Protobuf.Encode(w, wrapperspb.String("value"))
+	}))
+	defer svr.Close()
+	m, resp, err := Get[*wrapperspb.StringValue](context.TODO(), New(), svr.URL)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "value", m.GetValue())
+}
+
+func TestTypedHelpersErrors(t *testing.T) {
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer svr.Close()
+	client := New()
+	v, resp, err := Get[dummyType](context.TODO(), client, svr.URL)
+	require.NotNil(t, err)
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	require.Equal(t, dummyType{}, v)
+	_, resp, err = Post[func(), dummyType](context.TODO(), client, svr.URL, func() {})
+	require.NotNil(t, err)
+	require.Nil(t, resp)
+}